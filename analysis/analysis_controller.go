@@ -0,0 +1,281 @@
+package analysis
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.io/remast/c4stage/catalog"
+	"github.io/remast/c4stage/shared"
+	"github.io/remast/c4stage/shared/paged"
+	"schneider.vip/problem"
+)
+
+const (
+	defaultDepth     = 3
+	maxDepth         = 10
+	defaultMaxLength = 5
+)
+
+type AnalysisController struct {
+	Config     *shared.Config
+	Repository catalog.CatalogRepository
+	Rules      *RuleSet
+}
+
+type rulesModel struct {
+	Rules      []Rule      `json:"rules"`
+	Violations []Violation `json:"violations"`
+}
+
+type metricsModel struct {
+	Data        []Metrics `json:"data"`
+	*paged.Page `json:"page"`
+}
+
+type cyclesModel struct {
+	Data []Cycle `json:"data"`
+}
+
+func (c *AnalysisController) RegisterProtected(router chi.Router) {
+}
+
+func (c *AnalysisController) RegisterOpen(router chi.Router) {
+	r := chi.NewRouter()
+	router.Mount("/analysis", r)
+
+	r.Get("/cycles", c.HandleGetCycles())
+	r.Get("/impact/{ref}/{direction}", c.HandleGetImpact())
+	r.Get("/rules", c.HandleGetRules())
+	r.Get("/metrics", c.HandleGetMetrics())
+	r.Get("/paths/{from}/{to}", c.HandleGetPaths())
+}
+
+// HandleGetCycles finds all cyclic dependencies on the level given by query
+// parameter `level`, which is either `system` (default) or `container`.
+func (c *AnalysisController) HandleGetCycles() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		level, ok := LevelOf(r)
+		if !ok {
+			message := fmt.Sprintf("unknown level %v", level)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		landscape, err := c.Repository.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		cycles := Cycles(DependencyGraph(landscape, level), level)
+		if cycles == nil {
+			cycles = []Cycle{}
+		}
+
+		cyclesModel := cyclesModel{
+			Data: cycles,
+		}
+
+		shared.RenderJSON(w, cyclesModel)
+	}
+}
+
+// HandleGetImpact traverses all entities depending on (upstream) or needed
+// by (downstream) the entity with the given ref up to the depth given by
+// query parameter `depth`.
+func (c *AnalysisController) HandleGetImpact() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ref := chi.URLParam(r, "ref")
+		direction := chi.URLParam(r, "direction")
+		if direction != DirectionUpstream && direction != DirectionDownstream {
+			message := fmt.Sprintf("unknown direction %v", direction)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		depth, ok := DepthOf(r)
+		if !ok {
+			message := fmt.Sprintf("depth must be between 1 and %v", maxDepth)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		landscape, err := c.Repository.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		g := EntityGraph(landscape)
+		if !g.Contains(ref) {
+			message := fmt.Sprintf("entity %v not found", ref)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return
+		}
+
+		shared.RenderJSON(w, Traverse(g, ref, direction, depth))
+	}
+}
+
+// HandleGetRules evaluates the architecture fitness rules against the catalog.
+func (c *AnalysisController) HandleGetRules() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		landscape, err := c.Repository.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		rules := c.Rules.Rules
+		if rules == nil {
+			rules = []Rule{}
+		}
+
+		rulesModel := rulesModel{
+			Rules:      rules,
+			Violations: c.Rules.Evaluate(landscape),
+		}
+
+		shared.RenderJSON(w, rulesModel)
+	}
+}
+
+// HandleGetMetrics calculates the coupling metrics on the level given by
+// query parameter `level`, sorted by query parameter `sort` like
+// `-instability`. With `format=csv` all metrics are downloaded as CSV,
+// otherwise they are paged.
+func (c *AnalysisController) HandleGetMetrics() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		level, ok := LevelOf(r)
+		if !ok {
+			message := fmt.Sprintf("unknown level %v", level)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		landscape, err := c.Repository.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		metrics := CouplingMetrics(landscape, level)
+		err = SortMetrics(metrics, r.URL.Query().Get("sort"))
+		if err != nil {
+			http.Error(w, problem.New(problem.Title(err.Error())).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "csv" {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"metrics-%v.csv\"", level))
+			err = WriteMetricsCSV(w, metrics)
+			if err != nil {
+				shared.RenderProblemJSON(w, isProduction, err)
+			}
+			return
+		}
+
+		pageParams := paged.PageParamsOf(r)
+		start := min(max(pageParams.Offset(), 0), len(metrics))
+		end := min(start+max(pageParams.Size, 0), len(metrics))
+
+		metricsModel := metricsModel{
+			Data: metrics[start:end],
+			Page: pageParams.PageOfTotal(len(metrics)),
+		}
+
+		shared.RenderJSON(w, metricsModel)
+	}
+}
+
+// HandleGetPaths finds the shortest and all simple paths from the entity
+// with ref `from` to the entity with ref `to` with at most as many relations
+// as given by query parameter `maxLength`.
+func (c *AnalysisController) HandleGetPaths() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		from := chi.URLParam(r, "from")
+		to := chi.URLParam(r, "to")
+		if from == to {
+			http.Error(w, problem.New(problem.Title("from and to must differ")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		maxLength, ok := MaxLengthOf(r)
+		if !ok {
+			message := fmt.Sprintf("maxLength must be between 1 and %v", maxDepth)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		landscape, err := c.Repository.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		g := EntityGraph(landscape)
+		for _, ref := range []string{from, to} {
+			if !g.Contains(ref) {
+				message := fmt.Sprintf("entity %v not found", ref)
+				http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+				return
+			}
+		}
+
+		shared.RenderJSON(w, FindPaths(g, from, to, maxLength))
+	}
+}
+
+// DepthOf reads the depth of a traversal from query parameter `depth`.
+func DepthOf(r *http.Request) (int, bool) {
+	depthParam := r.URL.Query().Get("depth")
+	if depthParam == "" {
+		return defaultDepth, true
+	}
+
+	depth, err := strconv.Atoi(depthParam)
+	if err != nil || depth < 1 || depth > maxDepth {
+		return depth, false
+	}
+	return depth, true
+}
+
+// MaxLengthOf reads the maximum length of paths from query parameter `maxLength`.
+func MaxLengthOf(r *http.Request) (int, bool) {
+	maxLengthParam := r.URL.Query().Get("maxLength")
+	if maxLengthParam == "" {
+		return defaultMaxLength, true
+	}
+
+	maxLength, err := strconv.Atoi(maxLengthParam)
+	if err != nil || maxLength < 1 || maxLength > maxDepth {
+		return maxLength, false
+	}
+	return maxLength, true
+}
+
+// LevelOf reads the level of analysis from query parameter `level`.
+func LevelOf(r *http.Request) (string, bool) {
+	level := r.URL.Query().Get("level")
+	switch level {
+	case "":
+		return LevelSystem, true
+	case LevelSystem, LevelContainer:
+		return level, true
+	default:
+		return level, false
+	}
+}
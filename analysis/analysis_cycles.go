@@ -0,0 +1,110 @@
+package analysis
+
+import (
+	"slices"
+	"strings"
+)
+
+// Cycle is a strongly connected component of a graph, so every node of the
+// cycle depends on every other node of the cycle.
+type Cycle struct {
+	ID    int      `json:"id"`
+	Level string   `json:"level"`
+	Refs  []string `json:"refs"`
+	Edges []Edge   `json:"edges"`
+}
+
+// Cycles finds all cycles of the graph using Tarjan's algorithm for strongly
+// connected components. Cycles are numbered starting with one.
+func Cycles(g *Graph, level string) []Cycle {
+	t := &tarjan{
+		graph:   g,
+		index:   make(map[string]int),
+		lowLink: make(map[string]int),
+		onStack: make(map[string]bool),
+	}
+
+	for _, ref := range g.Refs() {
+		if _, visited := t.index[ref]; !visited {
+			t.connect(ref)
+		}
+	}
+
+	var cycles []Cycle
+	for _, component := range t.components {
+		slices.Sort(component)
+
+		var edges []Edge
+		for _, ref := range component {
+			for _, edge := range g.Outgoing(ref) {
+				if slices.Contains(component, edge.Target) {
+					edges = append(edges, edge)
+				}
+			}
+		}
+
+		// a single node only is a cycle if it depends on itself
+		if len(edges) == 0 {
+			continue
+		}
+
+		slices.SortFunc(edges, compareEdges)
+		cycles = append(cycles, Cycle{
+			Level: level,
+			Refs:  component,
+			Edges: edges,
+		})
+	}
+
+	slices.SortFunc(cycles, func(a Cycle, b Cycle) int {
+		return strings.Compare(a.Refs[0], b.Refs[0])
+	})
+	for i := range cycles {
+		cycles[i].ID = i + 1
+	}
+
+	return cycles
+}
+
+type tarjan struct {
+	graph      *Graph
+	counter    int
+	index      map[string]int
+	lowLink    map[string]int
+	onStack    map[string]bool
+	stack      []string
+	components [][]string
+}
+
+func (t *tarjan) connect(ref string) {
+	t.index[ref] = t.counter
+	t.lowLink[ref] = t.counter
+	t.counter++
+	t.stack = append(t.stack, ref)
+	t.onStack[ref] = true
+
+	for _, edge := range t.graph.Outgoing(ref) {
+		if _, visited := t.index[edge.Target]; !visited {
+			t.connect(edge.Target)
+			t.lowLink[ref] = min(t.lowLink[ref], t.lowLink[edge.Target])
+		} else if t.onStack[edge.Target] {
+			t.lowLink[ref] = min(t.lowLink[ref], t.index[edge.Target])
+		}
+	}
+
+	if t.lowLink[ref] != t.index[ref] {
+		return
+	}
+
+	var component []string
+	for {
+		top := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.onStack[top] = false
+		component = append(component, top)
+		if top == ref {
+			break
+		}
+	}
+	t.components = append(t.components, component)
+}
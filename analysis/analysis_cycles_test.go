@@ -0,0 +1,76 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.io/remast/c4stage/catalog"
+)
+
+func system(name string) catalog.System {
+	return catalog.System{EntityEnvelope: catalog.EntityEnvelope{Name: name, Title: name}}
+}
+
+func container(name string, system string) catalog.Container {
+	return catalog.Container{EntityEnvelope: catalog.EntityEnvelope{Name: name, Title: name}, System: system}
+}
+
+func dependsOn(source string, target string) catalog.Relation {
+	return catalog.Relation{Source: source, Target: target, Type: "DEPENDS_ON"}
+}
+
+func TestCyclesWithoutCycle(t *testing.T) {
+	is := is.New(t)
+
+	l := &catalog.Landscape{
+		Systems:   []catalog.System{system("a"), system("b")},
+		Relations: []catalog.Relation{dependsOn("system:a", "system:b")},
+	}
+
+	cycles := Cycles(DependencyGraph(l, LevelSystem), LevelSystem)
+
+	is.Equal(len(cycles), 0)
+}
+
+func TestCyclesOfSystems(t *testing.T) {
+	is := is.New(t)
+
+	l := &catalog.Landscape{
+		Systems: []catalog.System{system("a"), system("b"), system("c"), system("d")},
+		Relations: []catalog.Relation{
+			dependsOn("system:a", "system:b"),
+			dependsOn("system:b", "system:c"),
+			dependsOn("system:c", "system:a"),
+			dependsOn("system:c", "system:d"),
+			dependsOn("system:d", "system:d"),
+		},
+	}
+
+	cycles := Cycles(DependencyGraph(l, LevelSystem), LevelSystem)
+
+	is.Equal(len(cycles), 2)
+	is.Equal(cycles[0].ID, 1)
+	is.Equal(cycles[0].Refs, []string{"system:a", "system:b", "system:c"})
+	is.Equal(len(cycles[0].Edges), 3)
+	is.Equal(cycles[1].Refs, []string{"system:d"})
+}
+
+func TestCyclesOfContainers(t *testing.T) {
+	is := is.New(t)
+
+	l := &catalog.Landscape{
+		Systems:    []catalog.System{system("a")},
+		Containers: []catalog.Container{container("x", "a"), container("y", "a")},
+		Relations: []catalog.Relation{
+			dependsOn("component:x", "component:y"),
+			dependsOn("component:y", "component:x"),
+			dependsOn("component:x", "system:a"),
+		},
+	}
+
+	cycles := Cycles(DependencyGraph(l, LevelContainer), LevelContainer)
+
+	is.Equal(len(cycles), 1)
+	is.Equal(cycles[0].Level, LevelContainer)
+	is.Equal(cycles[0].Refs, []string{"component:x", "component:y"})
+}
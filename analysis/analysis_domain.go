@@ -0,0 +1,197 @@
+package analysis
+
+import (
+	"slices"
+	"strings"
+
+	"github.io/remast/c4stage/catalog"
+)
+
+const (
+	LevelSystem    = "system"
+	LevelContainer = "container"
+)
+
+// Node is an entity of the catalog within a graph.
+type Node struct {
+	Ref       string   `json:"ref"`
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Title     string   `json:"title"`
+	Type      string   `json:"type"`
+	Lifecycle string   `json:"lifecycle"`
+	System    string   `json:"system,omitempty"`
+	Tags      []string `json:"tags"`
+}
+
+// Edge is a directed relation between two nodes of a graph.
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+// Graph is a directed graph over entities of the catalog.
+type Graph struct {
+	Nodes    map[string]Node
+	Edges    []Edge
+	outgoing map[string][]Edge
+	incoming map[string][]Edge
+}
+
+// NewGraph creates a graph of the given nodes and all edges between them.
+func NewGraph(nodes []Node, edges []Edge) *Graph {
+	g := &Graph{
+		Nodes:    make(map[string]Node),
+		outgoing: make(map[string][]Edge),
+		incoming: make(map[string][]Edge),
+	}
+
+	for _, node := range nodes {
+		g.Nodes[node.Ref] = node
+	}
+
+	for _, edge := range edges {
+		if !g.Contains(edge.Source) || !g.Contains(edge.Target) {
+			continue
+		}
+		if slices.Contains(g.Edges, edge) {
+			continue
+		}
+		g.Edges = append(g.Edges, edge)
+		g.outgoing[edge.Source] = append(g.outgoing[edge.Source], edge)
+		g.incoming[edge.Target] = append(g.incoming[edge.Target], edge)
+	}
+
+	return g
+}
+
+// DependencyGraph creates the graph of DEPENDS_ON relations on the given
+// level, which is either between systems or between containers.
+func DependencyGraph(landscape *catalog.Landscape, level string) *Graph {
+	var nodes []Node
+	switch level {
+	case LevelContainer:
+		nodes = containerNodes(landscape)
+	default:
+		nodes = systemNodes(landscape)
+	}
+
+	var edges []Edge
+	for _, relation := range landscape.Relations {
+		if relation.Type != "DEPENDS_ON" {
+			continue
+		}
+		edges = append(edges, edgeOf(relation))
+	}
+
+	return NewGraph(nodes, edges)
+}
+
+// EntityGraph creates the graph of all systems, containers and APIs. All
+// edges point from the dependent entity to its dependency, so an API
+// depends on the system providing it.
+func EntityGraph(landscape *catalog.Landscape) *Graph {
+	var nodes []Node
+	nodes = append(nodes, systemNodes(landscape)...)
+	nodes = append(nodes, containerNodes(landscape)...)
+	nodes = append(nodes, apiNodes(landscape)...)
+
+	var edges []Edge
+	for _, relation := range landscape.Relations {
+		switch relation.Type {
+		case "DEPENDS_ON", "CONSUMES":
+			edges = append(edges, edgeOf(relation))
+		case "PROVIDES":
+			edges = append(edges, Edge{
+				Source: relation.Target,
+				Target: relation.Source,
+				Type:   "PROVIDED_BY",
+			})
+		}
+	}
+
+	return NewGraph(nodes, edges)
+}
+
+// Contains checks whether the graph contains the node with the given ref.
+func (g *Graph) Contains(ref string) bool {
+	_, ok := g.Nodes[ref]
+	return ok
+}
+
+// Refs returns the refs of all nodes of the graph in order.
+func (g *Graph) Refs() []string {
+	var refs []string
+	for ref := range g.Nodes {
+		refs = append(refs, ref)
+	}
+	slices.Sort(refs)
+	return refs
+}
+
+// Outgoing returns all edges starting at the node with the given ref.
+func (g *Graph) Outgoing(ref string) []Edge {
+	return g.outgoing[ref]
+}
+
+// Incoming returns all edges ending at the node with the given ref.
+func (g *Graph) Incoming(ref string) []Edge {
+	return g.incoming[ref]
+}
+
+func systemNodes(landscape *catalog.Landscape) []Node {
+	var nodes []Node
+	for _, system := range landscape.Systems {
+		nodes = append(nodes, nodeOf(system.Ref(), system.EntityEnvelope, ""))
+	}
+	return nodes
+}
+
+func containerNodes(landscape *catalog.Landscape) []Node {
+	var nodes []Node
+	for _, container := range landscape.Containers {
+		nodes = append(nodes, nodeOf(container.Ref(), container.EntityEnvelope, container.System))
+	}
+	return nodes
+}
+
+func apiNodes(landscape *catalog.Landscape) []Node {
+	var nodes []Node
+	for _, api := range landscape.APIs {
+		nodes = append(nodes, nodeOf(api.Ref(), api.EntityEnvelope, api.System))
+	}
+	return nodes
+}
+
+func nodeOf(ref string, envelope catalog.EntityEnvelope, system string) Node {
+	kind, _, _ := strings.Cut(ref, ":")
+	return Node{
+		Ref:       ref,
+		Kind:      kind,
+		Name:      envelope.Name,
+		Title:     envelope.Title,
+		Type:      envelope.Type,
+		Lifecycle: envelope.Lifecycle,
+		System:    system,
+		Tags:      envelope.Tags,
+	}
+}
+
+func edgeOf(relation catalog.Relation) Edge {
+	return Edge{
+		Source: relation.Source,
+		Target: relation.Target,
+		Type:   relation.Type,
+	}
+}
+
+func compareEdges(a Edge, b Edge) int {
+	if c := strings.Compare(a.Source, b.Source); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.Target, b.Target); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Type, b.Type)
+}
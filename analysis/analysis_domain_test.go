@@ -0,0 +1,45 @@
+package analysis
+
+import (
+	"github.io/remast/c4stage/catalog"
+)
+
+// testLandscape is the landscape of a web shop calling the external
+// payment service provider psp directly and through its gateway.
+func testLandscape() *catalog.Landscape {
+	return &catalog.Landscape{
+		Systems: []catalog.System{
+			system("gateway"),
+			system("orders"),
+			system("shop"),
+			{EntityEnvelope: catalog.EntityEnvelope{Name: "psp", Type: "external"}},
+		},
+		Containers: []catalog.Container{
+			container("orders-api", "orders"),
+			container("orders-db", "orders"),
+			{EntityEnvelope: catalog.EntityEnvelope{Name: "shop-ui", Type: "website", Lifecycle: "production"}, System: "shop"},
+			{EntityEnvelope: catalog.EntityEnvelope{Name: "shop-db", Type: "database", Lifecycle: "deprecated"}, System: "shop"},
+		},
+		APIs: []catalog.API{
+			{EntityEnvelope: catalog.EntityEnvelope{Name: "orders-rest"}, System: "orders"},
+			{EntityEnvelope: catalog.EntityEnvelope{Name: "payments"}, System: "psp"},
+		},
+		Relations: []catalog.Relation{
+			dependsOn("system:gateway", "system:psp"),
+			dependsOn("system:shop", "system:orders"),
+			dependsOn("system:shop", "system:psp"),
+			dependsOn("system:orders", "system:psp"),
+			dependsOn("system:orders", "component:orders-db"),
+			dependsOn("component:orders-api", "component:orders-db"),
+			dependsOn("component:orders-api", "system:psp"),
+			dependsOn("component:shop-ui", "component:orders-api"),
+			dependsOn("component:shop-ui", "component:shop-db"),
+			{Source: "component:shop-ui", Target: "api:orders-rest", Type: "CONSUMES"},
+			{Source: "component:shop-ui", Target: "api:payments", Type: "CONSUMES"},
+			{Source: "system:orders", Target: "api:orders-rest", Type: "PROVIDES"},
+			{Source: "system:psp", Target: "api:payments", Type: "PROVIDES"},
+			{Source: "system:shop", Target: "component:shop-ui", Type: "CONTAINS"},
+			{Source: "system:orders", Target: "component:orders-db", Type: "CONTAINS"},
+		},
+	}
+}
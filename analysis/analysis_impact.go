@@ -0,0 +1,92 @@
+package analysis
+
+import (
+	"slices"
+)
+
+const (
+	// DirectionUpstream follows all entities depending on an entity.
+	DirectionUpstream = "upstream"
+	// DirectionDownstream follows all entities an entity depends on.
+	DirectionDownstream = "downstream"
+	// DirectionBoth follows all entities depending on an entity or an entity
+	// depends on.
+	DirectionBoth = "both"
+)
+
+// Impact lists all entities reached from an entity in one direction.
+type Impact struct {
+	Ref       string `json:"ref"`
+	Direction string `json:"direction"`
+	Depth     int    `json:"depth"`
+	Hops      []Hop  `json:"hops"`
+}
+
+// Hop is an entity reached by the traversal with the shortest path taken.
+type Hop struct {
+	Node     Node   `json:"node"`
+	Distance int    `json:"distance"`
+	Path     []Edge `json:"path"`
+}
+
+// Traverse walks the graph breadth first from the entity with the given ref
+// in the given direction up to the given depth.
+func Traverse(g *Graph, ref string, direction string, depth int) *Impact {
+	impact := &Impact{
+		Ref:       ref,
+		Direction: direction,
+		Depth:     depth,
+		Hops:      []Hop{},
+	}
+
+	paths := map[string][]Edge{
+		ref: {},
+	}
+	frontier := []string{ref}
+
+	for distance := 1; distance <= depth && len(frontier) > 0; distance++ {
+		var next []string
+		for _, current := range frontier {
+			var edges []Edge
+			if direction != DirectionUpstream {
+				edges = append(edges, g.Outgoing(current)...)
+			}
+			if direction != DirectionDownstream {
+				edges = append(edges, g.Incoming(current)...)
+			}
+			slices.SortFunc(edges, compareEdges)
+
+			for _, edge := range edges {
+				reached := edge.Target
+				if reached == current {
+					reached = edge.Source
+				}
+				if _, visited := paths[reached]; visited {
+					continue
+				}
+
+				path := append(slices.Clone(paths[current]), edge)
+				paths[reached] = path
+				next = append(next, reached)
+
+				impact.Hops = append(impact.Hops, Hop{
+					Node:     g.Nodes[reached],
+					Distance: distance,
+					Path:     path,
+				})
+			}
+		}
+		frontier = next
+	}
+
+	return impact
+}
+
+// Refs returns the refs of the traversed entity and all reached entities.
+func (i Impact) Refs() []string {
+	refs := []string{i.Ref}
+	for _, hop := range i.Hops {
+		refs = append(refs, hop.Node.Ref)
+	}
+	return refs
+}
@@ -0,0 +1,51 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestTraverseUpstream(t *testing.T) {
+	is := is.New(t)
+
+	impact := Traverse(EntityGraph(testLandscape()), "component:orders-db", DirectionUpstream, 3)
+
+	is.Equal(len(impact.Hops), 5)
+	is.Equal(impact.Hops[0].Node.Ref, "component:orders-api")
+	is.Equal(impact.Hops[1].Node.Ref, "system:orders")
+	is.Equal(impact.Hops[2].Node.Ref, "component:shop-ui")
+	is.Equal(impact.Hops[2].Distance, 2)
+	is.Equal(impact.Hops[3].Node.Ref, "api:orders-rest")
+	is.Equal(len(impact.Hops[3].Path), 2)
+	is.Equal(impact.Hops[3].Path[1].Type, "PROVIDED_BY")
+}
+
+func TestTraverseUpstreamWithDepth(t *testing.T) {
+	is := is.New(t)
+
+	impact := Traverse(EntityGraph(testLandscape()), "component:orders-db", DirectionUpstream, 1)
+
+	is.Equal(len(impact.Hops), 2)
+	is.Equal(impact.Refs(), []string{"component:orders-db", "component:orders-api", "system:orders"})
+}
+
+func TestTraverseDownstream(t *testing.T) {
+	is := is.New(t)
+
+	impact := Traverse(EntityGraph(testLandscape()), "component:shop-ui", DirectionDownstream, 5)
+
+	is.Equal(len(impact.Hops), 7)
+	is.Equal(impact.Hops[5].Node.Ref, "system:psp")
+	is.Equal(impact.Hops[5].Path[1].Type, "PROVIDED_BY")
+	is.Equal(impact.Hops[6].Node.Ref, "component:orders-db")
+}
+
+func TestTraverseBoth(t *testing.T) {
+	is := is.New(t)
+
+	impact := Traverse(EntityGraph(testLandscape()), "api:orders-rest", DirectionBoth, 1)
+
+	is.Equal(impact.Refs(), []string{"api:orders-rest", "system:orders", "component:shop-ui"})
+	is.Equal(impact.Hops[1].Path[0].Source, "component:shop-ui")
+}
@@ -0,0 +1,183 @@
+package analysis
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.io/remast/c4stage/catalog"
+)
+
+// metricsSortFields are the fields metrics can be sorted by.
+var metricsSortFields = []string{
+	"name",
+	"afferent",
+	"efferent",
+	"instability",
+	"crossSystemDependencies",
+	"externalSystems",
+}
+
+// Metrics are the coupling metrics of a system or container.
+type Metrics struct {
+	Ref                     string  `json:"ref"`
+	Name                    string  `json:"name"`
+	System                  string  `json:"system,omitempty"`
+	Afferent                int     `json:"afferent"`
+	Efferent                int     `json:"efferent"`
+	Instability             float64 `json:"instability"`
+	CrossSystemDependencies int     `json:"crossSystemDependencies"`
+	ExternalSystems         int     `json:"externalSystems"`
+}
+
+// CouplingMetrics calculates the coupling metrics of all systems or
+// containers, depending on the level:
+//   - afferent coupling (Ca) counts the entities depending on an entity,
+//   - efferent coupling (Ce) counts the entities an entity depends on,
+//   - instability is Ce / (Ca + Ce), from 0 (stable) to 1 (instable),
+//   - cross system dependencies counts the other systems an entity depends on,
+//   - external systems counts the external systems an entity depends on.
+func CouplingMetrics(landscape *catalog.Landscape, level string) []Metrics {
+	g := DependencyGraph(landscape, level)
+
+	systemTypes := make(map[string]string)
+	for _, system := range landscape.Systems {
+		systemTypes[system.Name] = system.Type
+	}
+	systemsOfRefs := make(map[string]string)
+	for _, system := range landscape.Systems {
+		systemsOfRefs[system.Ref()] = system.Name
+	}
+	for _, container := range landscape.Containers {
+		systemsOfRefs[container.Ref()] = container.System
+	}
+
+	// other systems each entity depends on
+	dependencies := make(map[string][]string)
+	for _, relation := range landscape.Relations {
+		if relation.Type != "DEPENDS_ON" || !g.Contains(relation.Source) {
+			continue
+		}
+
+		ownSystem := systemsOfRefs[relation.Source]
+		targetSystem, ok := systemsOfRefs[relation.Target]
+		if !ok || targetSystem == "" || targetSystem == ownSystem {
+			continue
+		}
+		if !slices.Contains(dependencies[relation.Source], targetSystem) {
+			dependencies[relation.Source] = append(dependencies[relation.Source], targetSystem)
+		}
+	}
+
+	metrics := []Metrics{}
+	for _, ref := range g.Refs() {
+		node := g.Nodes[ref]
+
+		m := Metrics{
+			Ref:                     ref,
+			Name:                    node.Name,
+			System:                  node.System,
+			Afferent:                len(g.Incoming(ref)),
+			Efferent:                len(g.Outgoing(ref)),
+			CrossSystemDependencies: len(dependencies[ref]),
+		}
+		if m.Afferent+m.Efferent > 0 {
+			m.Instability = float64(m.Efferent) / float64(m.Afferent+m.Efferent)
+		}
+		for _, system := range dependencies[ref] {
+			if systemTypes[system] == "external" {
+				m.ExternalSystems++
+			}
+		}
+
+		metrics = append(metrics, m)
+	}
+
+	return metrics
+}
+
+// SortMetrics sorts the metrics by the given field, descending if the field
+// is prefixed with `-` like `-instability`. Ties are sorted by name and
+// ref.
+func SortMetrics(metrics []Metrics, sort string) error {
+	field, descending := strings.CutPrefix(sort, "-")
+	if field == "" {
+		field = "name"
+	}
+	if !slices.Contains(metricsSortFields, field) {
+		return fmt.Errorf("unknown sort field %v", field)
+	}
+
+	slices.SortStableFunc(metrics, func(a Metrics, b Metrics) int {
+		var c int
+		switch field {
+		case "afferent":
+			c = cmp.Compare(a.Afferent, b.Afferent)
+		case "efferent":
+			c = cmp.Compare(a.Efferent, b.Efferent)
+		case "instability":
+			c = cmp.Compare(a.Instability, b.Instability)
+		case "crossSystemDependencies":
+			c = cmp.Compare(a.CrossSystemDependencies, b.CrossSystemDependencies)
+		case "externalSystems":
+			c = cmp.Compare(a.ExternalSystems, b.ExternalSystems)
+		}
+		if descending {
+			c = -c
+		}
+		if c == 0 {
+			c = strings.Compare(a.Name, b.Name)
+			if c == 0 {
+				c = strings.Compare(a.Ref, b.Ref)
+			}
+			if descending && field == "name" {
+				c = -c
+			}
+		}
+		return c
+	})
+
+	return nil
+}
+
+// WriteMetricsCSV writes the metrics as CSV with a header row.
+func WriteMetricsCSV(w io.Writer, metrics []Metrics) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{
+		"ref",
+		"name",
+		"system",
+		"afferent",
+		"efferent",
+		"instability",
+		"crossSystemDependencies",
+		"externalSystems",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, m := range metrics {
+		err = writer.Write([]string{
+			m.Ref,
+			m.Name,
+			m.System,
+			strconv.Itoa(m.Afferent),
+			strconv.Itoa(m.Efferent),
+			strconv.FormatFloat(m.Instability, 'f', 2, 64),
+			strconv.Itoa(m.CrossSystemDependencies),
+			strconv.Itoa(m.ExternalSystems),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
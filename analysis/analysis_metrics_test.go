@@ -0,0 +1,111 @@
+package analysis
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func metricsOf(metrics []Metrics, ref string) Metrics {
+	for _, m := range metrics {
+		if m.Ref == ref {
+			return m
+		}
+	}
+	return Metrics{}
+}
+
+func TestCouplingMetricsOfSystems(t *testing.T) {
+	is := is.New(t)
+
+	metrics := CouplingMetrics(testLandscape(), LevelSystem)
+
+	is.Equal(len(metrics), 4)
+
+	shop := metricsOf(metrics, "system:shop")
+	is.Equal(shop.Afferent, 0)
+	is.Equal(shop.Efferent, 2)
+	is.Equal(shop.Instability, 1.0)
+	is.Equal(shop.CrossSystemDependencies, 2)
+	is.Equal(shop.ExternalSystems, 1)
+
+	psp := metricsOf(metrics, "system:psp")
+	is.Equal(psp.Afferent, 3)
+	is.Equal(psp.Instability, 0.0)
+
+	orders := metricsOf(metrics, "system:orders")
+	is.Equal(orders.Instability, 0.5)
+}
+
+func TestCouplingMetricsOfContainers(t *testing.T) {
+	is := is.New(t)
+
+	metrics := CouplingMetrics(testLandscape(), LevelContainer)
+
+	is.Equal(len(metrics), 4)
+
+	api := metricsOf(metrics, "component:orders-api")
+	is.Equal(api.System, "orders")
+	is.Equal(api.Afferent, 1)
+	is.Equal(api.Efferent, 1)
+	is.Equal(api.CrossSystemDependencies, 1)
+	is.Equal(api.ExternalSystems, 1)
+
+	ui := metricsOf(metrics, "component:shop-ui")
+	is.Equal(ui.CrossSystemDependencies, 1)
+	is.Equal(ui.ExternalSystems, 0)
+}
+
+func TestSortMetricsDescending(t *testing.T) {
+	is := is.New(t)
+
+	metrics := CouplingMetrics(testLandscape(), LevelSystem)
+
+	err := SortMetrics(metrics, "-instability")
+
+	is.NoErr(err)
+	is.Equal(metrics[0].Ref, "system:gateway")
+	is.Equal(metrics[1].Ref, "system:shop")
+	is.Equal(metrics[2].Ref, "system:orders")
+	is.Equal(metrics[3].Ref, "system:psp")
+}
+
+func TestSortMetricsByUnknownField(t *testing.T) {
+	is := is.New(t)
+
+	err := SortMetrics([]Metrics{}, "color")
+
+	is.True(err != nil)
+}
+
+func TestWriteMetricsCSV(t *testing.T) {
+	is := is.New(t)
+
+	metrics := CouplingMetrics(testLandscape(), LevelSystem)
+	var buf bytes.Buffer
+
+	err := WriteMetricsCSV(&buf, metrics)
+
+	is.NoErr(err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	is.Equal(len(lines), 5)
+	is.Equal(lines[0], "ref,name,system,afferent,efferent,instability,crossSystemDependencies,externalSystems")
+	is.Equal(lines[2], "system:orders,orders,,1,1,0.50,1,1")
+}
+
+func TestSortMetricsTiesByName(t *testing.T) {
+	is := is.New(t)
+
+	metrics := []Metrics{
+		{Ref: "component:a", Name: "web"},
+		{Ref: "component:b", Name: "api"},
+	}
+
+	err := SortMetrics(metrics, "instability")
+
+	is.NoErr(err)
+	is.Equal(metrics[0].Name, "api")
+	is.Equal(metrics[1].Name, "web")
+}
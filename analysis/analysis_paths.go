@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"slices"
+)
+
+// maxPaths is the maximum number of paths found, as densely connected
+// entities have more simple paths than can be listed.
+const maxPaths = 100
+
+// Paths lists the ways from one entity to another.
+type Paths struct {
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	MaxLength int      `json:"maxLength"`
+	Shortest  []Edge   `json:"shortest"`
+	All       [][]Edge `json:"all"`
+	// Truncated is set if there are more than maxPaths paths.
+	Truncated bool `json:"truncated"`
+}
+
+// FindPaths finds the shortest path and all simple paths with at most
+// maxLength edges from the entity from to the entity to, but no more than
+// maxPaths. Paths are sorted by length. Without a path Shortest is nil and
+// All is empty.
+func FindPaths(g *Graph, from string, to string, maxLength int) *Paths {
+	paths := &Paths{
+		From:      from,
+		To:        to,
+		MaxLength: maxLength,
+		All:       [][]Edge{},
+	}
+
+	for _, hop := range Traverse(g, from, DirectionDownstream, maxLength).Hops {
+		if hop.Node.Ref == to {
+			paths.Shortest = hop.Path
+		}
+	}
+	if paths.Shortest == nil {
+		return paths
+	}
+
+	// distances of all entities reaching the entity to, so the walk skips
+	// entities too far away to reach it
+	distances := map[string]int{to: 0}
+	for _, hop := range Traverse(g, to, DirectionUpstream, maxLength).Hops {
+		distances[hop.Node.Ref] = hop.Distance
+	}
+
+	visited := map[string]bool{from: true}
+	var walk func(current string, path []Edge)
+	walk = func(current string, path []Edge) {
+		if paths.Truncated {
+			return
+		}
+		if current == to {
+			if len(paths.All) == maxPaths {
+				paths.Truncated = true
+				return
+			}
+			paths.All = append(paths.All, slices.Clone(path))
+			return
+		}
+
+		edges := slices.Clone(g.Outgoing(current))
+		slices.SortFunc(edges, compareEdges)
+		for _, edge := range edges {
+			if visited[edge.Target] {
+				continue
+			}
+			distance, ok := distances[edge.Target]
+			if !ok || len(path)+1+distance > maxLength {
+				continue
+			}
+			visited[edge.Target] = true
+			walk(edge.Target, append(path, edge))
+			visited[edge.Target] = false
+		}
+	}
+	walk(from, []Edge{})
+
+	slices.SortStableFunc(paths.All, func(a []Edge, b []Edge) int {
+		return len(a) - len(b)
+	})
+
+	return paths
+}
+
+// Refs returns the refs of all entities on any of the paths.
+func (p Paths) Refs() []string {
+	refs := []string{p.From}
+	for _, path := range p.All {
+		for _, edge := range path {
+			if !slices.Contains(refs, edge.Target) {
+				refs = append(refs, edge.Target)
+			}
+		}
+	}
+	return refs
+}
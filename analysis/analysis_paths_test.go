@@ -0,0 +1,73 @@
+package analysis
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.io/remast/c4stage/catalog"
+)
+
+func TestFindPathsViaAPI(t *testing.T) {
+	is := is.New(t)
+
+	paths := FindPaths(EntityGraph(testLandscape()), "component:shop-ui", "system:psp", 5)
+
+	is.Equal(len(paths.Shortest), 2)
+	is.Equal(paths.Shortest[0].Type, "CONSUMES")
+	is.Equal(paths.Shortest[1].Type, "PROVIDED_BY")
+	is.Equal(len(paths.All), 3)
+	is.Equal(paths.Refs(), []string{"component:shop-ui", "api:payments", "system:psp", "component:orders-api", "api:orders-rest", "system:orders"})
+}
+
+func TestFindAllSimplePaths(t *testing.T) {
+	is := is.New(t)
+
+	paths := FindPaths(EntityGraph(testLandscape()), "system:shop", "system:psp", 5)
+
+	is.Equal(len(paths.Shortest), 1)
+	is.Equal(len(paths.All), 2)
+	is.Equal(len(paths.All[0]), 1)
+	is.Equal(len(paths.All[1]), 2)
+}
+
+func TestFindPathsWithMaxLength(t *testing.T) {
+	is := is.New(t)
+
+	paths := FindPaths(EntityGraph(testLandscape()), "system:shop", "system:psp", 1)
+
+	is.Equal(len(paths.All), 1)
+}
+
+func TestFindPathsWithoutPath(t *testing.T) {
+	is := is.New(t)
+
+	paths := FindPaths(EntityGraph(testLandscape()), "system:psp", "system:shop", 5)
+
+	is.Equal(paths.Shortest, nil)
+	is.Equal(len(paths.All), 0)
+}
+
+func TestFindPathsTruncated(t *testing.T) {
+	is := is.New(t)
+
+	l := &catalog.Landscape{
+		Systems: []catalog.System{system("shop"), system("psp")},
+	}
+	for i := 0; i < 11; i++ {
+		l.Systems = append(l.Systems, system(fmt.Sprintf("a%v", i)), system(fmt.Sprintf("b%v", i)))
+		l.Relations = append(l.Relations,
+			dependsOn("system:shop", fmt.Sprintf("system:a%v", i)),
+			dependsOn(fmt.Sprintf("system:b%v", i), "system:psp"),
+		)
+		for j := 0; j < 11; j++ {
+			l.Relations = append(l.Relations, dependsOn(fmt.Sprintf("system:a%v", i), fmt.Sprintf("system:b%v", j)))
+		}
+	}
+
+	paths := FindPaths(EntityGraph(l), "system:shop", "system:psp", 5)
+
+	is.Equal(len(paths.Shortest), 3)
+	is.Equal(len(paths.All), maxPaths)
+	is.True(paths.Truncated)
+}
@@ -0,0 +1,194 @@
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.io/remast/c4stage/catalog"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleSet is a set of architecture fitness rules as loaded from a rules file
+// like:
+//
+//	rules:
+//	  - name: no-deprecated-dependencies
+//	    description: No production component may depend on a deprecated one.
+//	    from: { kind: component, lifecycles: [production] }
+//	    to: { lifecycles: [deprecated] }
+//	  - name: external-via-gateway
+//	    description: Only the gateway system may depend on external systems.
+//	    only: true
+//	    from: { systems: [gateway] }
+//	    to: { types: [external] }
+type RuleSet struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Rule forbids all dependencies from entities matching From to entities
+// matching To. If Only is set the rule forbids all dependencies to entities
+// matching To from entities not matching From instead.
+type Rule struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description"`
+	Severity    string   `yaml:"severity" json:"severity"`
+	Only        bool     `yaml:"only" json:"only"`
+	From        Selector `yaml:"from" json:"from"`
+	To          Selector `yaml:"to" json:"to"`
+}
+
+// Selector selects entities of the catalog. An entity matches if it matches
+// all given criteria, so an empty selector matches all entities. Names and
+// systems may be given as patterns like `orders-*`.
+type Selector struct {
+	Kind       string   `yaml:"kind" json:"kind,omitempty"`
+	Names      []string `yaml:"names" json:"names,omitempty"`
+	Types      []string `yaml:"types" json:"types,omitempty"`
+	Lifecycles []string `yaml:"lifecycles" json:"lifecycles,omitempty"`
+	Tags       []string `yaml:"tags" json:"tags,omitempty"`
+	Systems    []string `yaml:"systems" json:"systems,omitempty"`
+}
+
+// Violation is a relation violating a rule.
+type Violation struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Type     string `json:"type"`
+	Message  string `json:"message"`
+}
+
+// ruleRelationTypes are the types of relations rules are evaluated against.
+var ruleRelationTypes = []string{"DEPENDS_ON", "CONSUMES"}
+
+// LoadRuleSet reads the rules from the given file, without file there
+// are no rules.
+func LoadRuleSet(fileName string) (*RuleSet, error) {
+	ruleSet := &RuleSet{}
+	if fileName == "" {
+		return ruleSet, nil
+	}
+
+	fileBytes, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewBuffer(fileBytes))
+	decoder.KnownFields(true)
+	err = decoder.Decode(ruleSet)
+	if err != nil {
+		return nil, fmt.Errorf("rules decode failed: %w", err)
+	}
+
+	for i, rule := range ruleSet.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %v has no name", i+1)
+		}
+		if rule.Severity == "" {
+			ruleSet.Rules[i].Severity = catalog.SeverityError
+		}
+	}
+
+	return ruleSet, nil
+}
+
+// Evaluate checks all relations of the landscape against the rules.
+func (s *RuleSet) Evaluate(landscape *catalog.Landscape) []Violation {
+	violations := []Violation{}
+
+	g := EntityGraph(landscape)
+	for _, relation := range landscape.Relations {
+		if !slices.Contains(ruleRelationTypes, relation.Type) {
+			continue
+		}
+
+		source, ok := g.Nodes[relation.Source]
+		if !ok {
+			continue
+		}
+		target, ok := g.Nodes[relation.Target]
+		if !ok {
+			continue
+		}
+
+		for _, rule := range s.Rules {
+			if !rule.Violates(source, target) {
+				continue
+			}
+
+			message := rule.Description
+			if message == "" {
+				message = fmt.Sprintf("%v must not depend on %v", source.Ref, target.Ref)
+			}
+
+			violations = append(violations, Violation{
+				Rule:     rule.Name,
+				Severity: rule.Severity,
+				Source:   relation.Source,
+				Target:   relation.Target,
+				Type:     relation.Type,
+				Message:  message,
+			})
+		}
+	}
+
+	return violations
+}
+
+// Violates checks whether a dependency from source to target violates the rule.
+func (r Rule) Violates(source Node, target Node) bool {
+	if !r.To.Matches(target) {
+		return false
+	}
+	if r.Only {
+		return !r.From.Matches(source)
+	}
+	return r.From.Matches(source)
+}
+
+// Matches checks whether the node matches all criteria of the selector.
+func (s Selector) Matches(node Node) bool {
+	if s.Kind != "" && s.Kind != node.Kind {
+		return false
+	}
+	if len(s.Names) > 0 && !matchesAnyPattern(s.Names, node.Name) {
+		return false
+	}
+	if len(s.Types) > 0 && !slices.Contains(s.Types, node.Type) {
+		return false
+	}
+	if len(s.Lifecycles) > 0 && !slices.Contains(s.Lifecycles, node.Lifecycle) {
+		return false
+	}
+	if len(s.Tags) > 0 && !slices.ContainsFunc(s.Tags, func(tag string) bool {
+		return slices.Contains(node.Tags, tag)
+	}) {
+		return false
+	}
+	if len(s.Systems) > 0 {
+		system := node.System
+		if node.Kind == "system" {
+			system = node.Name
+		}
+		if !matchesAnyPattern(s.Systems, system) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAnyPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+		if err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
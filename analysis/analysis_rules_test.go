@@ -0,0 +1,89 @@
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.io/remast/c4stage/catalog"
+)
+
+func TestLoadRuleSetWithoutFile(t *testing.T) {
+	is := is.New(t)
+
+	ruleSet, err := LoadRuleSet("")
+
+	is.NoErr(err)
+	is.Equal(len(ruleSet.Rules), 0)
+}
+
+func TestLoadRuleSetExample(t *testing.T) {
+	is := is.New(t)
+
+	ruleSet, err := LoadRuleSet(filepath.Join("..", "doc", "rules.example.yaml"))
+
+	is.NoErr(err)
+	is.Equal(len(ruleSet.Rules), 3)
+	is.Equal(ruleSet.Rules[2].Severity, catalog.SeverityError)
+}
+
+func TestLoadRuleSetWithoutName(t *testing.T) {
+	is := is.New(t)
+
+	fileName := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(fileName, []byte("rules:\n  - description: no name\n"), 0600)
+	is.NoErr(err)
+
+	_, err = LoadRuleSet(fileName)
+
+	is.True(err != nil)
+}
+
+func TestEvaluateForbiddenDependency(t *testing.T) {
+	is := is.New(t)
+
+	ruleSet := &RuleSet{Rules: []Rule{{
+		Name: "no-frontend-to-database",
+		From: Selector{Types: []string{"website"}},
+		To:   Selector{Types: []string{"database"}},
+	}}}
+
+	violations := ruleSet.Evaluate(testLandscape())
+
+	is.Equal(len(violations), 1)
+	is.Equal(violations[0].Source, "component:shop-ui")
+	is.Equal(violations[0].Target, "component:shop-db")
+}
+
+func TestEvaluateOnlyDependency(t *testing.T) {
+	is := is.New(t)
+
+	ruleSet := &RuleSet{Rules: []Rule{{
+		Name: "external-via-gateway",
+		Only: true,
+		From: Selector{Systems: []string{"gate*"}},
+		To:   Selector{Kind: "system", Types: []string{"external"}},
+	}}}
+
+	violations := ruleSet.Evaluate(testLandscape())
+
+	is.Equal(len(violations), 3)
+	is.Equal(violations[0].Source, "system:shop")
+	is.Equal(violations[0].Message, "system:shop must not depend on system:psp")
+}
+
+func TestEvaluateLifecycles(t *testing.T) {
+	is := is.New(t)
+
+	ruleSet := &RuleSet{Rules: []Rule{{
+		Name: "no-deprecated-dependencies",
+		From: Selector{Kind: "component", Lifecycles: []string{"production"}},
+		To:   Selector{Lifecycles: []string{"deprecated"}},
+	}}}
+
+	violations := ruleSet.Evaluate(testLandscape())
+
+	is.Equal(len(violations), 1)
+	is.Equal(violations[0].Rule, "no-deprecated-dependencies")
+}
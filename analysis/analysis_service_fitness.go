@@ -0,0 +1,40 @@
+package analysis
+
+import (
+	"context"
+	"log"
+
+	"github.io/remast/c4stage/catalog"
+)
+
+var _ catalog.ImportListener = (*FitnessService)(nil)
+
+// FitnessService evaluates the architecture fitness rules after every import
+// and marks all violating relations in the catalog.
+type FitnessService struct {
+	Rules      *RuleSet
+	Repository catalog.CatalogRepository
+}
+
+func (s *FitnessService) Imported(ctx context.Context) error {
+	landscape, err := s.Repository.FindLandscape(ctx)
+	if err != nil {
+		return err
+	}
+
+	violations := s.Rules.Evaluate(landscape)
+
+	violatedRules := make(map[catalog.Relation][]string)
+	for _, violation := range violations {
+		relation := catalog.Relation{
+			Source: violation.Source,
+			Target: violation.Target,
+			Type:   violation.Type,
+		}
+		violatedRules[relation] = append(violatedRules[relation], violation.Rule)
+	}
+
+	log.Printf("Found %v violations of architecture rules.", len(violations))
+
+	return s.Repository.MarkViolations(ctx, violatedRules)
+}
@@ -1,6 +1,7 @@
 package backstage
 
 import (
+	"fmt"
 	"io"
 	"log"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 type ImportController struct {
 	Config            *shared.Config
 	CatalogRepository catalog.CatalogRepository
+	Listeners         []catalog.ImportListener
 }
 
 func (c *ImportController) RegisterProtected(router chi.Router) {
@@ -23,29 +25,55 @@ func (c *ImportController) RegisterOpen(router chi.Router) {
 	router.Mount("/backstage", r)
 
 	r.Get("/import", c.HandleImportFromBackstage())
+	r.Get("/export", c.HandleExportToBackstage())
 }
 
 func (c *ImportController) HandleImportFromBackstage() http.HandlerFunc {
 	backstageImportService := BackstageImporter{
 		Config:     c.Config,
 		Repository: c.CatalogRepository,
+		Listeners:  c.Listeners,
 	}
 	isProduction := c.Config.IsProduction()
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := c.CatalogRepository.Reset(r.Context())
+		log.Println("Importing Backstage Catalog ...")
+		io.WriteString(w, "Importing Backstage Catalog ...")
+		err := backstageImportService.ImportBackstageCatalog()
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+		}
+		log.Println("Successfully imported Backstage Catalog.")
+		io.WriteString(w, "Successfully imported Backstage Catalog.")
+	}
+}
+
+// HandleExportToBackstage exports the catalog as Backstage entities, as
+// multi document YAML stream or with `format=zip` as zip archive with one
+// file per system.
+func (c *ImportController) HandleExportToBackstage() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		landscape, err := c.CatalogRepository.FindLandscape(r.Context())
 		if err != nil {
 			shared.RenderProblemJSON(w, isProduction, err)
 			return
 		}
 
-		log.Println("Importing Backstage Catalog ...")
-		io.WriteString(w, "Importing Backstage Catalog ...")
-		err = backstageImportService.ImportBackstageCatalog()
+		exported := ExportLandscape(landscape)
+
+		switch r.URL.Query().Get("format") {
+		case "zip":
+			w.Header().Set("Content-Type", "application/zip")
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "catalog-info.zip"))
+			err = WriteZip(w, exported)
+		default:
+			w.Header().Set("Content-Type", "application/yaml")
+			err = WriteYAML(w, exported)
+		}
 		if err != nil {
 			shared.RenderProblemJSON(w, isProduction, err)
 		}
-		log.Println("Successfully imported Backstage Catalog.")
-		io.WriteString(w, "Successfully imported Backstage Catalog.")
 	}
 }
@@ -2,36 +2,66 @@ package backstage
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.io/remast/c4stage/catalog"
 )
 
+// AnnotationComponents lists the modules of a component as comma separated
+// names, which are imported as subcomponents of the component.
+const AnnotationComponents = "c4stage.io/components"
+
+// AnnotationDeploymentPrefix prefixes the annotations placing an entity in
+// an environment like `c4stage.io/deployment.production: aws-prod/eks-cluster`.
+const AnnotationDeploymentPrefix = "c4stage.io/deployment."
+
+// AnnotationUsagePrefix prefixes the annotations describing the use of a
+// consumed API like `c4stage.io/usage.orders-api.description: places orders`
+// and `c4stage.io/usage.orders-api.technology: REST/JSON`.
+const AnnotationUsagePrefix = "c4stage.io/usage."
+
+// deploymentNodeTypes are the types of resources imported as deployment nodes.
+var deploymentNodeTypes = []string{
+	"cloud-account",
+	"aws-account",
+	"datacenter",
+	"kubernetes-cluster",
+	"kubernetes-namespace",
+	"server",
+	"vm",
+}
+
 type RawEntity struct {
 	APIVersion string      `json:"apiVersion" yaml:"apiVersion"`
 	Kind       string      `json:"kind" yaml:"kind"`
-	Namespace  string      `json:"namespace" yaml:"namespace"`
+	Namespace  string      `json:"namespace" yaml:"namespace,omitempty"`
 	Metadata   RawMetadata `json:"metadata" yaml:"metadata"`
 	Spec       RawSpec     `json:"spec" yaml:"spec"`
-	Links      []Link      `json:"links" yaml:"links"`
+	Links      []Link      `json:"links" yaml:"links,omitempty"`
 }
 
 type RawMetadata struct {
-	Name        string   `json:"name" yaml:"name"`
-	Title       string   `json:"title" yaml:"title"`
-	Description string   `json:"description" yaml:"description"`
-	Owner       string   `json:"owner" yaml:"owner"`
-	Domain      string   `json:"domain" yaml:"domain"`
-	Tags        []string `json:"tags" yaml:"tags"`
+	Name        string            `json:"name" yaml:"name"`
+	Title       string            `json:"title" yaml:"title,omitempty"`
+	Description string            `json:"description" yaml:"description,omitempty"`
+	Owner       string            `json:"owner" yaml:"owner,omitempty"`
+	Domain      string            `json:"domain" yaml:"domain,omitempty"`
+	Tags        []string          `json:"tags" yaml:"tags,omitempty"`
+	Annotations map[string]string `json:"annotations" yaml:"annotations,omitempty"`
 }
 
 type RawSpec struct {
-	Type         string   `json:"type" yaml:"type"`
-	System       string   `json:"system" yaml:"system"`
-	ConsumesAPIs []string `json:"consumesApis" yaml:"consumesApis"`
-	ProvidesAPIs []string `json:"providesApis" yaml:"providesApis"`
-	Definition   string   `json:"definition" yaml:"definition"`
-	DependsOn    []string `json:"dependsOn" yaml:"dependsOn"`
-	Lifecycle    string   `json:"lifecycle" yaml:"lifecycle"`
+	Type           string   `json:"type" yaml:"type,omitempty"`
+	System         string   `json:"system" yaml:"system,omitempty"`
+	ConsumesAPIs   []string `json:"consumesApis" yaml:"consumesApis,omitempty"`
+	ProvidesAPIs   []string `json:"providesApis" yaml:"providesApis,omitempty"`
+	Definition     string   `json:"definition" yaml:"definition,omitempty"`
+	DependsOn      []string `json:"dependsOn" yaml:"dependsOn,omitempty"`
+	Lifecycle      string   `json:"lifecycle" yaml:"lifecycle,omitempty"`
+	Owner          string   `json:"owner" yaml:"owner,omitempty"`
+	Domain         string   `json:"domain" yaml:"domain,omitempty"`
+	SubcomponentOf string   `json:"subcomponentOf" yaml:"subcomponentOf,omitempty"`
 }
 
 type Link struct {
@@ -44,9 +74,18 @@ func (rawEntity RawEntity) EntityEnvelopeFromRaw() catalog.EntityEnvelope {
 		Description: rawEntity.Metadata.Description,
 		Kind:        rawEntity.Kind,
 		Lifecycle:   rawEntity.Spec.Lifecycle,
+		Owner:       rawEntity.Spec.Owner,
+		Domain:      rawEntity.Spec.Domain,
 		Tags:        rawEntity.Metadata.Tags,
 		Type:        rawEntity.Spec.Type,
 	}
+	// Backstage keeps owner and domain in the spec, older catalogs in the metadata
+	if envelope.Owner == "" {
+		envelope.Owner = rawEntity.Metadata.Owner
+	}
+	if envelope.Domain == "" {
+		envelope.Domain = rawEntity.Metadata.Domain
+	}
 	return envelope
 }
 
@@ -61,6 +100,18 @@ func (rawEntity RawEntity) FromRaw() (any, error) {
 		}
 		entity = system
 	case "Component":
+		if rawEntity.Spec.SubcomponentOf != "" {
+			_, container, _ := strings.Cut(rawEntity.Spec.SubcomponentOf, ":")
+			if container == "" {
+				container = rawEntity.Spec.SubcomponentOf
+			}
+			entity = catalog.SubComponent{
+				EntityEnvelope: envelope,
+				Container:      container,
+				DependsOn:      rawEntity.Spec.DependsOn,
+			}
+			break
+		}
 		if rawEntity.Spec.Type != "service" &&
 			rawEntity.Spec.Type != "database" {
 			return nil, fmt.Errorf("could not convert component of type %s", rawEntity.Spec.Type)
@@ -68,14 +119,33 @@ func (rawEntity RawEntity) FromRaw() (any, error) {
 		container := catalog.Container{
 			EntityEnvelope: envelope,
 			ConsumesAPIs:   rawEntity.Spec.ConsumesAPIs,
+			ProvidesAPIs:   rawEntity.Spec.ProvidesAPIs,
 			DependsOn:      rawEntity.Spec.DependsOn,
 			System:         rawEntity.Spec.System,
+			Deployments:    rawEntity.Deployments(),
+			Usages:         rawEntity.Usages(),
+		}
+		entity = container
+	case "Resource":
+		if slices.Contains(deploymentNodeTypes, rawEntity.Spec.Type) {
+			entity = catalog.DeploymentNode{
+				EntityEnvelope: envelope,
+				Deployments:    rawEntity.Deployments(),
+			}
+			break
+		}
+		container := catalog.Container{
+			EntityEnvelope: envelope,
+			DependsOn:      rawEntity.Spec.DependsOn,
+			System:         rawEntity.Spec.System,
+			Deployments:    rawEntity.Deployments(),
 		}
 		entity = container
 	case "API":
 		api := catalog.API{
 			EntityEnvelope: envelope,
 			System:         rawEntity.Spec.System,
+			Definition:     rawEntity.Spec.Definition,
 		}
 		entity = api
 	default:
@@ -84,3 +154,143 @@ func (rawEntity RawEntity) FromRaw() (any, error) {
 
 	return entity, nil
 }
+
+// Deployments reads the paths of deployment nodes by environment from the
+// deployment annotations.
+func (rawEntity RawEntity) Deployments() map[string]string {
+	var deployments map[string]string
+	for key, path := range rawEntity.Metadata.Annotations {
+		env, ok := strings.CutPrefix(key, AnnotationDeploymentPrefix)
+		if !ok || env == "" {
+			continue
+		}
+		if deployments == nil {
+			deployments = make(map[string]string)
+		}
+		deployments[env] = strings.Trim(path, "/ ")
+	}
+	return deployments
+}
+
+// Usages reads the description and technology of the use of consumed APIs
+// from the annotations prefixed with `c4stage.io/usage.`.
+func (rawEntity RawEntity) Usages() map[string]catalog.Usage {
+	var props []string
+	for key, value := range rawEntity.Metadata.Annotations {
+		prop, ok := strings.CutPrefix(key, AnnotationUsagePrefix)
+		if ok {
+			props = append(props, prop+"="+value)
+		}
+	}
+	return catalog.UsagesFromProps(props)
+}
+
+// AnnotatedSubComponents reads the subcomponents of a component given by
+// annotation `c4stage.io/components`.
+func (rawEntity RawEntity) AnnotatedSubComponents() []any {
+	if rawEntity.Kind != "Component" {
+		return nil
+	}
+
+	var subComponents []any
+	for _, name := range strings.Split(rawEntity.Metadata.Annotations[AnnotationComponents], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		subComponents = append(subComponents, catalog.SubComponent{
+			EntityEnvelope: catalog.EntityEnvelope{
+				Name:      name,
+				Title:     name,
+				Kind:      "Component",
+				Lifecycle: rawEntity.Spec.Lifecycle,
+			},
+			Container: rawEntity.Metadata.Name,
+		})
+	}
+	return subComponents
+}
+
+// rawEntityOf converts the envelope of an entity into a raw entity of the
+// given kind.
+func rawEntityOf(kind string, envelope catalog.EntityEnvelope) RawEntity {
+	rawEntity := RawEntity{
+		APIVersion: "backstage.io/v1alpha1",
+		Kind:       kind,
+		Metadata: RawMetadata{
+			Name:        envelope.Name,
+			Description: envelope.Description,
+			Tags:        envelope.Tags,
+		},
+		Spec: RawSpec{
+			Type:      envelope.Type,
+			Lifecycle: envelope.Lifecycle,
+			Owner:     envelope.Owner,
+			Domain:    envelope.Domain,
+		},
+	}
+	if envelope.Title != envelope.Name {
+		rawEntity.Metadata.Title = envelope.Title
+	}
+	return rawEntity
+}
+
+func RawEntityFromSystem(system catalog.System) RawEntity {
+	rawEntity := rawEntityOf("System", system.EntityEnvelope)
+	rawEntity.Spec.DependsOn = system.DependsOn
+	return rawEntity
+}
+
+func RawEntityFromContainer(container catalog.Container) RawEntity {
+	kind := container.Kind
+	if kind != "Resource" {
+		kind = "Component"
+	}
+
+	rawEntity := rawEntityOf(kind, container.EntityEnvelope)
+	rawEntity.Spec.System = container.System
+	rawEntity.Spec.DependsOn = container.DependsOn
+	for env, path := range container.Deployments {
+		if rawEntity.Metadata.Annotations == nil {
+			rawEntity.Metadata.Annotations = make(map[string]string)
+		}
+		rawEntity.Metadata.Annotations[AnnotationDeploymentPrefix+env] = path
+	}
+	for _, prop := range catalog.UsagesToProps(container.Usages) {
+		if rawEntity.Metadata.Annotations == nil {
+			rawEntity.Metadata.Annotations = make(map[string]string)
+		}
+		key, value, _ := strings.Cut(prop, "=")
+		rawEntity.Metadata.Annotations[AnnotationUsagePrefix+key] = value
+	}
+	if kind == "Component" {
+		rawEntity.Spec.ConsumesAPIs = container.ConsumesAPIs
+		rawEntity.Spec.ProvidesAPIs = container.ProvidesAPIs
+	}
+	return rawEntity
+}
+
+func RawEntityFromSubComponent(subComponent catalog.SubComponent) RawEntity {
+	rawEntity := rawEntityOf("Component", subComponent.EntityEnvelope)
+	rawEntity.Spec.SubcomponentOf = catalog.EntityRef("Component", subComponent.Container)
+	rawEntity.Spec.DependsOn = subComponent.DependsOn
+	return rawEntity
+}
+
+func RawEntityFromDeploymentNode(deploymentNode catalog.DeploymentNode) RawEntity {
+	rawEntity := rawEntityOf("Resource", deploymentNode.EntityEnvelope)
+	for env, path := range deploymentNode.Deployments {
+		if rawEntity.Metadata.Annotations == nil {
+			rawEntity.Metadata.Annotations = make(map[string]string)
+		}
+		rawEntity.Metadata.Annotations[AnnotationDeploymentPrefix+env] = path
+	}
+	return rawEntity
+}
+
+func RawEntityFromAPI(api catalog.API) RawEntity {
+	rawEntity := rawEntityOf("API", api.EntityEnvelope)
+	rawEntity.Spec.System = api.System
+	rawEntity.Spec.Definition = api.Definition
+	return rawEntity
+}
@@ -0,0 +1,118 @@
+package backstage
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.io/remast/c4stage/catalog"
+)
+
+func TestFromRawSubcomponent(t *testing.T) {
+	is := is.New(t)
+
+	rawEntity := RawEntity{
+		Kind:     "Component",
+		Metadata: RawMetadata{Name: "billing"},
+		Spec:     RawSpec{Type: "library", SubcomponentOf: "component:shop-api"},
+	}
+
+	entity, err := rawEntity.FromRaw()
+
+	is.NoErr(err)
+	is.Equal(entity.(catalog.SubComponent).Container, "shop-api")
+}
+
+func TestAnnotatedSubComponents(t *testing.T) {
+	is := is.New(t)
+
+	rawEntity := RawEntity{
+		Kind: "Component",
+		Metadata: RawMetadata{
+			Name:        "shop-api",
+			Annotations: map[string]string{AnnotationComponents: "billing, invoicing,"},
+		},
+	}
+
+	subComponents := rawEntity.AnnotatedSubComponents()
+
+	is.Equal(len(subComponents), 2)
+	is.Equal(subComponents[1].(catalog.SubComponent).Name, "invoicing")
+	is.Equal(subComponents[1].(catalog.SubComponent).Container, "shop-api")
+}
+
+func TestFromRawDeploymentNode(t *testing.T) {
+	is := is.New(t)
+
+	rawEntity := RawEntity{
+		Kind: "Resource",
+		Metadata: RawMetadata{
+			Name:        "eks-cluster",
+			Annotations: map[string]string{AnnotationDeploymentPrefix + "production": "aws-prod/"},
+		},
+		Spec: RawSpec{Type: "kubernetes-cluster"},
+	}
+
+	entity, err := rawEntity.FromRaw()
+
+	is.NoErr(err)
+	is.Equal(entity.(catalog.DeploymentNode).Deployments, map[string]string{"production": "aws-prod"})
+}
+
+func TestFromRawDeployedComponent(t *testing.T) {
+	is := is.New(t)
+
+	rawEntity := RawEntity{
+		Kind: "Component",
+		Metadata: RawMetadata{
+			Name: "orders-api",
+			Annotations: map[string]string{
+				AnnotationDeploymentPrefix + "production": "eks-cluster/orders",
+				"backstage.io/techdocs-ref":               "dir:.",
+			},
+		},
+		Spec: RawSpec{Type: "service"},
+	}
+
+	entity, err := rawEntity.FromRaw()
+
+	is.NoErr(err)
+	is.Equal(entity.(catalog.Container).Deployments, map[string]string{"production": "eks-cluster/orders"})
+}
+
+func TestEntityEnvelopeFromRawOwnerAndDomain(t *testing.T) {
+	is := is.New(t)
+
+	rawEntity := RawEntity{
+		Kind:     "System",
+		Metadata: RawMetadata{Name: "shop", Domain: "commerce"},
+		Spec:     RawSpec{Owner: "team-shop"},
+	}
+
+	envelope := rawEntity.EntityEnvelopeFromRaw()
+
+	is.Equal(envelope.Owner, "team-shop")
+	is.Equal(envelope.Domain, "commerce")
+}
+
+func TestFromRawComponentWithUsages(t *testing.T) {
+	is := is.New(t)
+
+	rawEntity := RawEntity{
+		Kind: "Component",
+		Metadata: RawMetadata{
+			Name: "shop-api",
+			Annotations: map[string]string{
+				AnnotationUsagePrefix + "orders-api.description": "places orders",
+				AnnotationUsagePrefix + "orders-api.technology":  "REST/JSON",
+			},
+		},
+		Spec: RawSpec{Type: "service", ConsumesAPIs: []string{"orders-api"}},
+	}
+
+	entity, err := rawEntity.FromRaw()
+	container := entity.(catalog.Container)
+
+	is.NoErr(err)
+	is.Equal(container.Usages["orders-api"], catalog.Usage{Description: "places orders", Technology: "REST/JSON"})
+	is.Equal(RawEntityFromContainer(container).Metadata.Annotations, rawEntity.Metadata.Annotations)
+}
@@ -0,0 +1,135 @@
+package backstage
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.io/remast/c4stage/catalog"
+	"gopkg.in/yaml.v3"
+)
+
+// unassignedFileName is the name of the file in the zip export holding all
+// entities not belonging to any system.
+const unassignedFileName = "catalog-info.yaml"
+
+// ExportedEntity is an entity of the catalog converted back to Backstage,
+// together with the system it belongs to.
+type ExportedEntity struct {
+	System string
+	Entity RawEntity
+}
+
+// ExportLandscape converts all entities defined in the landscape back to
+// Backstage entities. Entities only referenced by others are skipped as
+// Backstage knows them from elsewhere.
+func ExportLandscape(landscape *catalog.Landscape) []ExportedEntity {
+	var exported []ExportedEntity
+
+	for _, system := range landscape.Systems {
+		if !system.Defined {
+			continue
+		}
+		exported = append(exported, ExportedEntity{
+			System: system.Name,
+			Entity: RawEntityFromSystem(system),
+		})
+	}
+	for _, container := range landscape.Containers {
+		if !container.Defined {
+			continue
+		}
+		exported = append(exported, ExportedEntity{
+			System: container.System,
+			Entity: RawEntityFromContainer(container),
+		})
+	}
+	for _, subComponent := range landscape.SubComponents {
+		if !subComponent.Defined {
+			continue
+		}
+		exported = append(exported, ExportedEntity{
+			System: systemOfContainer(landscape, subComponent.Container),
+			Entity: RawEntityFromSubComponent(subComponent),
+		})
+	}
+	for _, api := range landscape.APIs {
+		if !api.Defined {
+			continue
+		}
+		exported = append(exported, ExportedEntity{
+			System: api.System,
+			Entity: RawEntityFromAPI(api),
+		})
+	}
+	for _, deploymentNode := range landscape.DeploymentNodes {
+		if !deploymentNode.Defined {
+			continue
+		}
+		exported = append(exported, ExportedEntity{
+			Entity: RawEntityFromDeploymentNode(deploymentNode),
+		})
+	}
+
+	return exported
+}
+
+// systemOfContainer returns the name of the system of the container with
+// the given name, or the empty string for unknown containers.
+func systemOfContainer(landscape *catalog.Landscape, name string) string {
+	for _, container := range landscape.Containers {
+		if container.Name == name {
+			return container.System
+		}
+	}
+	return ""
+}
+
+// WriteYAML writes the entities as one multi document YAML stream.
+func WriteYAML(w io.Writer, exported []ExportedEntity) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	for _, e := range exported {
+		err := encoder.Encode(e.Entity)
+		if err != nil {
+			return fmt.Errorf("document encode failed: %w", err)
+		}
+	}
+
+	return encoder.Close()
+}
+
+// WriteZip writes the entities as zip archive with one YAML file per
+// system like `my-system.yaml`.
+func WriteZip(w io.Writer, exported []ExportedEntity) error {
+	entitiesOfFiles := make(map[string][]ExportedEntity)
+	var fileNames []string
+	for _, e := range exported {
+		fileName := unassignedFileName
+		if e.System != "" {
+			fileName = e.System + ".yaml"
+		}
+		if !slices.Contains(fileNames, fileName) {
+			fileNames = append(fileNames, fileName)
+		}
+		entitiesOfFiles[fileName] = append(entitiesOfFiles[fileName], e)
+	}
+	slices.Sort(fileNames)
+
+	zw := zip.NewWriter(w)
+	for _, fileName := range fileNames {
+		fw, err := zw.Create(fileName)
+		if err != nil {
+			return err
+		}
+
+		err = WriteYAML(fw, entitiesOfFiles[fileName])
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
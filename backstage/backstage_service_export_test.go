@@ -0,0 +1,148 @@
+package backstage
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.io/remast/c4stage/catalog"
+	"gopkg.in/yaml.v3"
+)
+
+func exportLandscape() *catalog.Landscape {
+	return &catalog.Landscape{
+		Systems: []catalog.System{
+			{EntityEnvelope: catalog.EntityEnvelope{Name: "shop", Title: "Web Shop", Defined: true}, DependsOn: []string{"system:psp"}},
+			{EntityEnvelope: catalog.EntityEnvelope{Name: "psp", Title: "psp"}},
+		},
+		Containers: []catalog.Container{
+			{
+				EntityEnvelope: catalog.EntityEnvelope{Name: "shop-ui", Title: "shop-ui", Type: "service", Defined: true},
+				System:         "shop",
+				ConsumesAPIs:   []string{"orders-api"},
+				DependsOn:      []string{"resource:shop-db"},
+			},
+			{
+				EntityEnvelope: catalog.EntityEnvelope{Name: "shop-db", Title: "shop-db", Kind: "Resource", Type: "database", Defined: true},
+				System:         "shop",
+			},
+		},
+		APIs: []catalog.API{
+			{
+				EntityEnvelope: catalog.EntityEnvelope{Name: "orders-api", Title: "orders-api", Type: "openapi", Owner: "team-orders", Defined: true},
+				Definition:     "openapi: 3.0.0",
+			},
+		},
+	}
+}
+
+func TestExportLandscapeSkipsUndefinedEntities(t *testing.T) {
+	is := is.New(t)
+
+	exported := ExportLandscape(exportLandscape())
+
+	is.Equal(len(exported), 4)
+	is.Equal(exported[0].Entity.Kind, "System")
+	is.Equal(exported[0].Entity.Metadata.Title, "Web Shop")
+	is.Equal(exported[1].Entity.Kind, "Component")
+	is.Equal(exported[2].Entity.Kind, "Resource")
+	is.Equal(exported[3].Entity.Kind, "API")
+}
+
+func TestExportLandscapeWithSubComponentsAndDeploymentNodes(t *testing.T) {
+	is := is.New(t)
+
+	l := &catalog.Landscape{
+		Containers: []catalog.Container{
+			{EntityEnvelope: catalog.EntityEnvelope{Name: "shop-api", Type: "service"}, System: "shop"},
+		},
+		SubComponents: []catalog.SubComponent{
+			{EntityEnvelope: catalog.EntityEnvelope{Name: "cart", Defined: true}, Container: "shop-api", DependsOn: []string{"component:pricing"}},
+		},
+		DeploymentNodes: []catalog.DeploymentNode{
+			{
+				EntityEnvelope: catalog.EntityEnvelope{Name: "eks-cluster", Type: "kubernetes-cluster", Defined: true},
+				Deployments:    map[string]string{"production": "aws-prod"},
+			},
+			{EntityEnvelope: catalog.EntityEnvelope{Name: "aws-prod"}},
+		},
+	}
+
+	exported := ExportLandscape(l)
+
+	is.Equal(len(exported), 2)
+	is.Equal(exported[0].System, "shop")
+
+	subComponent, err := exported[0].Entity.FromRaw()
+	is.NoErr(err)
+	is.Equal(subComponent.(catalog.SubComponent).Container, "shop-api")
+	is.Equal(subComponent.(catalog.SubComponent).DependsOn, []string{"component:pricing"})
+
+	deploymentNode, err := exported[1].Entity.FromRaw()
+	is.NoErr(err)
+	is.Equal(deploymentNode.(catalog.DeploymentNode).Deployments, map[string]string{"production": "aws-prod"})
+}
+
+func TestWriteYAMLRoundTrip(t *testing.T) {
+	is := is.New(t)
+
+	var buf bytes.Buffer
+	err := WriteYAML(&buf, ExportLandscape(exportLandscape()))
+	is.NoErr(err)
+
+	decoder := yaml.NewDecoder(&buf)
+	var entities []any
+	for {
+		var rawEntity RawEntity
+		err := decoder.Decode(&rawEntity)
+		if err == io.EOF {
+			break
+		}
+		is.NoErr(err)
+
+		entity, err := rawEntity.FromRaw()
+		is.NoErr(err)
+		entities = append(entities, entity)
+	}
+
+	is.Equal(len(entities), 4)
+
+	system := entities[0].(catalog.System)
+	is.Equal(system.DependsOn, []string{"system:psp"})
+
+	container := entities[1].(catalog.Container)
+	is.Equal(container.System, "shop")
+	is.Equal(container.ConsumesAPIs, []string{"orders-api"})
+	is.Equal(container.DependsOn, []string{"resource:shop-db"})
+
+	resource := entities[2].(catalog.Container)
+	is.Equal(resource.Kind, "Resource")
+
+	api := entities[3].(catalog.API)
+	is.Equal(api.Owner, "team-orders")
+	is.Equal(api.Definition, "openapi: 3.0.0")
+}
+
+func TestWriteZipWithFilePerSystem(t *testing.T) {
+	is := is.New(t)
+
+	var buf bytes.Buffer
+	err := WriteZip(&buf, ExportLandscape(exportLandscape()))
+	is.NoErr(err)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	is.NoErr(err)
+
+	is.Equal(len(zr.File), 2)
+	is.Equal(zr.File[0].Name, "catalog-info.yaml")
+	is.Equal(zr.File[1].Name, "shop.yaml")
+
+	f, err := zr.File[1].Open()
+	is.NoErr(err)
+	content, err := io.ReadAll(f)
+	is.NoErr(err)
+	is.Equal(strings.Count(string(content), "kind:"), 3)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -18,11 +19,12 @@ import (
 type BackstageImporter struct {
 	Config     *shared.Config
 	Repository catalog.CatalogRepository
+	Listeners  []catalog.ImportListener
 }
 
 func (i BackstageImporter) ImportBackstageCatalog() error {
 	backstageURL := fmt.Sprintf(
-		"%v/api/catalog/entities?offset=0&limit=500&filter=kind=component&filter=kind=system&filter=kind=api",
+		"%v/api/catalog/entities?offset=0&limit=500&filter=kind=component&filter=kind=system&filter=kind=api&filter=kind=resource",
 		i.Config.BackstageServer,
 	)
 	response, err := http.Get(backstageURL)
@@ -40,9 +42,10 @@ func (i BackstageImporter) ImportBackstageCatalog() error {
 	for _, rawEntity := range rawEntities {
 		entity, _ := rawEntity.FromRaw()
 		entities = append(entities, entity)
+		entities = append(entities, rawEntity.AnnotatedSubComponents()...)
 	}
 
-	return i.Repository.CreateAll(context.Background(), entities)
+	return i.importEntities(context.Background(), i.Config.BackstageServer, entities)
 }
 
 func (i BackstageImporter) ImportYamlFiles() error {
@@ -69,8 +72,40 @@ func (i BackstageImporter) ImportYamlFiles() error {
 			}
 
 			entities = append(entities, entity)
+			entities = append(entities, d.AnnotatedSubComponents()...)
 		}
 	}
 
-	return i.Repository.CreateAll(context.Background(), entities)
+	return i.importEntities(context.Background(), "yaml", entities)
+}
+
+// importEntities replaces the live graph with the entities, notifies all
+// listeners and records the result as a new snapshot of the catalog.
+func (i BackstageImporter) importEntities(ctx context.Context, source string, entities []any) error {
+	// the import always replaces the live graph
+	ctx = shared.ContextWithSnapshot(ctx, shared.LiveSnapshot)
+	err := i.Repository.Reset(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = i.Repository.CreateAll(ctx, entities)
+	if err != nil {
+		return err
+	}
+
+	for _, listener := range i.Listeners {
+		err = listener.Imported(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	snapshot, err := i.Repository.CreateSnapshot(ctx, source, i.Config.SnapshotRetention)
+	if err != nil {
+		return err
+	}
+	log.Printf("Recorded snapshot %v of catalog.", snapshot.ID)
+
+	return nil
 }
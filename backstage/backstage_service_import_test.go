@@ -0,0 +1,43 @@
+package backstage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.io/remast/c4stage/catalog"
+	"github.io/remast/c4stage/shared"
+)
+
+type recordingRepository struct {
+	catalog.CatalogRepository
+	calls []string
+}
+
+func (r *recordingRepository) Reset(ctx context.Context) error {
+	r.calls = append(r.calls, "reset:"+shared.SnapshotFromContext(ctx))
+	return nil
+}
+
+func (r *recordingRepository) CreateAll(ctx context.Context, entities []any) error {
+	r.calls = append(r.calls, "create:"+shared.SnapshotFromContext(ctx))
+	return nil
+}
+
+func (r *recordingRepository) CreateSnapshot(ctx context.Context, source string, retention int) (*catalog.Snapshot, error) {
+	r.calls = append(r.calls, "snapshot")
+	return &catalog.Snapshot{ID: "s1"}, nil
+}
+
+func TestImportEntitiesReplacesLiveGraph(t *testing.T) {
+	is := is.New(t)
+
+	repository := &recordingRepository{}
+	importer := BackstageImporter{Config: &shared.Config{}, Repository: repository}
+
+	ctx := shared.ContextWithSnapshot(context.Background(), "older")
+	err := importer.importEntities(ctx, "yaml", nil)
+
+	is.NoErr(err)
+	is.Equal(repository.calls, []string{"reset:" + shared.LiveSnapshot, "create:" + shared.LiveSnapshot, "snapshot"})
+}
@@ -2,13 +2,17 @@ package c4
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.io/remast/c4stage/analysis"
+	"github.io/remast/c4stage/catalog"
 	"github.io/remast/c4stage/shared"
 	"schneider.vip/problem"
 )
@@ -16,102 +20,421 @@ import (
 type C4Controller struct {
 	Config     *shared.Config
 	Repository C4Repository
+	Catalog    catalog.CatalogRepository
+	// Exporters export diagrams, by default in all known formats.
+	Exporters *ExporterRegistry
+}
+
+type flowsModel struct {
+	Data []Flow `json:"data"`
 }
 
 func (c *C4Controller) RegisterProtected(router chi.Router) {
 }
 
 func (c *C4Controller) RegisterOpen(router chi.Router) {
+	if c.Exporters == nil {
+		c.Exporters = NewDefaultExporterRegistry(c.Config)
+	}
+
 	r := chi.NewRouter()
 	router.Mount("/c4", r)
 
 	r.Get("/context", c.HandleGetSystemLandscapeDiagram())
 	r.Get("/container", c.HandleGetSystemLandscapeContainerDiagram())
+	r.Get("/{name}/context", c.HandleGetSystemContextDiagram())
 	r.Get("/{name}/container", c.HandleGetContainerDiagram())
+	r.Get("/{system}/{container}/component", c.HandleGetComponentDiagram())
+	r.Get("/{system}/deployment", c.HandleGetDeploymentDiagram())
+	// the static `/api` takes priority, so `api` is reserved and no valid
+	// name of a system
+	r.Get("/api/{name}", c.HandleGetAPIDiagram())
+	r.Get("/{system}/flows", c.HandleGetFlows())
+	r.Get("/{system}/flows/{flow}", c.HandleGetFlowDiagram())
+	r.Put("/{system}/flows/{flow}", c.HandlePutFlow())
+	r.Delete("/{system}/flows/{flow}", c.HandleDeleteFlow())
+
+	// diagrams not of a single system are below `/-`, which is no valid
+	// name of a system, so they never shadow the diagrams of a system
+	r.Route("/-", func(r chi.Router) {
+		r.Get("/diff/context", c.HandleGetDiffSystemLandscapeDiagram())
+		r.Get("/diff/container", c.HandleGetDiffSystemLandscapeContainerDiagram())
+		r.Get("/cycles/{level}/{id}", c.HandleGetCycleDiagram())
+		r.Get("/impact/{ref}/{direction}", c.HandleGetImpactDiagram())
+		r.Get("/paths/{from}/{to}", c.HandleGetPathsDiagram())
+		r.Get("/neighbourhood/{ref}", c.HandleGetNeighbourhoodDiagram())
+		r.Get("/structurizr", c.HandleGetStructurizrWorkspace())
+	})
 }
 
 func (c *C4Controller) HandleGetSystemLandscapeDiagram() http.HandlerFunc {
 	e := newPlantUMLExporter()
-	plantUMLServer := c.Config.PlantUMLServer
 	isProduction := c.Config.IsProduction()
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		c4Model, err := c.Repository.SystemLandscapeDiagram(r.Context())
+		c4Model, err := c.Repository.SystemLandscapeDiagram(r.Context(), DiagramFilterOf(r))
 		if err != nil {
 			shared.RenderProblemJSON(w, isProduction, err)
 			return
 		}
 
-		// adjust scale
-		scale, _ := strconv.ParseFloat(r.URL.Query().Get("scale"), 64)
-		c4Model.Scale = scale
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLContext)
+	}
+}
+
+func (c *C4Controller) HandleGetSystemLandscapeContainerDiagram() http.HandlerFunc {
+	e := newPlantUMLExporter()
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		c4Model, err := c.Repository.SystemLandscapeContainerDiagram(r.Context(), DiagramFilterOf(r))
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
 
-		// adjust image format
-		imageFormat := r.URL.Query().Get("format")
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLContainer)
+	}
+}
 
-		sw := bytes.NewBufferString("")
+func (c *C4Controller) HandleGetContainerDiagram() http.HandlerFunc {
+	e := newPlantUMLExporter()
+	isProduction := c.Config.IsProduction()
 
-		err = e.ExportToPlantUMLContext(c4Model, sw)
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "name")
+		c4Model, err := c.Repository.ContainerDiagram(r.Context(), name, DiagramFilterOf(r))
 		if err != nil {
 			shared.RenderProblemJSON(w, isProduction, err)
 			return
 		}
 
-		renderPlantUML(
-			w,
-			sw.String(),
-			imageFormat,
-			plantUMLServer,
-			isProduction,
-		)
+		if c4Model.IsEmpty() {
+			message := fmt.Sprintf("system %v not found", name)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return
+		}
+
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLContainer)
 	}
 }
 
-func (c *C4Controller) HandleGetSystemLandscapeContainerDiagram() http.HandlerFunc {
+// fromSnapshot returns the snapshot to compare from given by query parameter
+// `from`, rendering a problem if it is missing or unknown.
+func (c *C4Controller) fromSnapshot(w http.ResponseWriter, r *http.Request) (string, bool) {
+	from := r.URL.Query().Get("from")
+	if from == "" {
+		http.Error(w, problem.New(problem.Title("missing snapshot to compare from")).JSONString(), http.StatusBadRequest)
+		return "", false
+	}
+
+	if from != shared.LiveSnapshot {
+		snapshot, err := c.Catalog.FindSnapshot(r.Context(), from)
+		if err != nil {
+			shared.RenderProblemJSON(w, c.Config.IsProduction(), err)
+			return "", false
+		}
+		if snapshot == nil {
+			message := fmt.Sprintf("snapshot %v not found", from)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return "", false
+		}
+	}
+
+	return from, true
+}
+
+// HandleGetDiffSystemLandscapeDiagram renders the changes between the snapshot
+// given by query parameter `from` and the snapshot the request is scoped to.
+func (c *C4Controller) HandleGetDiffSystemLandscapeDiagram() http.HandlerFunc {
 	e := newPlantUMLExporter()
-	plantUMLServer := c.Config.PlantUMLServer
 	isProduction := c.Config.IsProduction()
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		c4Model, err := c.Repository.SystemLandscapeContainerDiagram(r.Context())
+		from, ok := c.fromSnapshot(w, r)
+		if !ok {
+			return
+		}
+
+		fromModel, err := c.Repository.SystemLandscapeDiagram(shared.ContextWithSnapshot(r.Context(), from), DiagramFilterOf(r))
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		toModel, err := c.Repository.SystemLandscapeDiagram(r.Context(), DiagramFilterOf(r))
 		if err != nil {
 			shared.RenderProblemJSON(w, isProduction, err)
 			return
 		}
 
-		// adjust scale
-		scale, _ := strconv.ParseFloat(r.URL.Query().Get("scale"), 64)
-		c4Model.Scale = scale
+		c4Model := DiffModels(fromModel, toModel)
+
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLContext)
+	}
+}
 
-		// adjust image format
-		imageFormat := r.URL.Query().Get("format")
+// HandleGetDiffSystemLandscapeContainerDiagram renders the changes between the
+// snapshot given by query parameter `from` and the snapshot the request is
+// scoped to.
+func (c *C4Controller) HandleGetDiffSystemLandscapeContainerDiagram() http.HandlerFunc {
+	e := newPlantUMLExporter()
+	isProduction := c.Config.IsProduction()
 
-		sw := bytes.NewBufferString("")
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, ok := c.fromSnapshot(w, r)
+		if !ok {
+			return
+		}
 
-		err = e.ExportToPlantUMLContainer(c4Model, sw)
+		fromModel, err := c.Repository.SystemLandscapeContainerDiagram(shared.ContextWithSnapshot(r.Context(), from), DiagramFilterOf(r))
 		if err != nil {
 			shared.RenderProblemJSON(w, isProduction, err)
 			return
 		}
 
-		renderPlantUML(
-			w,
-			sw.String(),
-			imageFormat,
-			plantUMLServer,
-			isProduction,
-		)
+		toModel, err := c.Repository.SystemLandscapeContainerDiagram(r.Context(), DiagramFilterOf(r))
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		c4Model := DiffModels(fromModel, toModel)
+
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLContainer)
 	}
 }
 
-func (c *C4Controller) HandleGetContainerDiagram() http.HandlerFunc {
+// HandleGetCycleDiagram renders the entities and relations of one cyclic
+// dependency as found by the cycle analysis.
+func (c *C4Controller) HandleGetCycleDiagram() http.HandlerFunc {
+	e := newPlantUMLExporter()
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		level := chi.URLParam(r, "level")
+		if level != analysis.LevelSystem && level != analysis.LevelContainer {
+			message := fmt.Sprintf("unknown level %v", level)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+		landscape, err := c.Catalog.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		cycles := analysis.Cycles(analysis.DependencyGraph(landscape, level), level)
+		index := slices.IndexFunc(cycles, func(cycle analysis.Cycle) bool {
+			return cycle.ID == id
+		})
+		if index == -1 {
+			message := fmt.Sprintf("cycle %v not found", chi.URLParam(r, "id"))
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return
+		}
+		cycle := cycles[index]
+
+		c4Model := LandscapeDiagram(landscape, cycle.Refs)
+		for _, ref := range cycle.Refs {
+			c4Model.TagElement(ref, "cycle")
+		}
+		for _, edge := range cycle.Edges {
+			c4Model.TagRelation(edge.Source, edge.Target, "cycle")
+		}
+
+		export := e.ExportToPlantUMLContext
+		if level == analysis.LevelContainer {
+			export = e.ExportToPlantUMLContainer
+		}
+		c.renderModel(w, r, c4Model, export)
+	}
+}
+
+// HandleGetImpactDiagram renders the neighbourhood impacted by the entity
+// with the given ref, colouring the elements by their distance in hops.
+func (c *C4Controller) HandleGetImpactDiagram() http.HandlerFunc {
+	e := newPlantUMLExporter()
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ref := chi.URLParam(r, "ref")
+		direction := chi.URLParam(r, "direction")
+		if direction != analysis.DirectionUpstream && direction != analysis.DirectionDownstream {
+			message := fmt.Sprintf("unknown direction %v", direction)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		depth, ok := analysis.DepthOf(r)
+		if !ok {
+			message := fmt.Sprintf("invalid depth %v", r.URL.Query().Get("depth"))
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		landscape, err := c.Catalog.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		g := analysis.EntityGraph(landscape)
+		if !g.Contains(ref) {
+			message := fmt.Sprintf("entity %v not found", ref)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return
+		}
+
+		impact := analysis.Traverse(g, ref, direction, depth)
+
+		c4Model := ImpactDiagram(landscape, impact)
+
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLContainer)
+	}
+}
+
+// HandleGetPathsDiagram renders all entities on the paths from the entity
+// with ref `from` to the entity with ref `to`.
+func (c *C4Controller) HandleGetPathsDiagram() http.HandlerFunc {
+	e := newPlantUMLExporter()
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		from := chi.URLParam(r, "from")
+		to := chi.URLParam(r, "to")
+		if from == to {
+			http.Error(w, problem.New(problem.Title("from and to must differ")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		maxLength, ok := analysis.MaxLengthOf(r)
+		if !ok {
+			message := fmt.Sprintf("invalid maxLength %v", r.URL.Query().Get("maxLength"))
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		landscape, err := c.Catalog.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		g := analysis.EntityGraph(landscape)
+		for _, ref := range []string{from, to} {
+			if !g.Contains(ref) {
+				message := fmt.Sprintf("entity %v not found", ref)
+				http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+				return
+			}
+		}
+
+		paths := analysis.FindPaths(g, from, to, maxLength)
+		if len(paths.All) == 0 {
+			message := fmt.Sprintf("no path from %v to %v", from, to)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return
+		}
+
+		var relations [][]catalog.Relation
+		for _, path := range paths.All {
+			var pathRelations []catalog.Relation
+			for _, edge := range path {
+				pathRelations = append(pathRelations, catalog.Relation{
+					Source: edge.Source,
+					Target: edge.Target,
+					Type:   edge.Type,
+				})
+			}
+			relations = append(relations, pathRelations)
+		}
+
+		c4Model := PathDiagram(landscape, from, to, relations)
+
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLContainer)
+	}
+}
+
+// HandleGetStructurizrWorkspace exports the catalog as Structurizr workspace,
+// in the DSL or as JSON with `format=json`.
+func (c *C4Controller) HandleGetStructurizrWorkspace() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		landscape, err := c.Catalog.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		workspace := StructurizrWorkspaceOf(landscape)
+
+		if r.URL.Query().Get("format") == "json" {
+			shared.RenderJSON(w, workspace)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		err = ExportToStructurizrDSL(workspace, w)
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+	}
+}
+
+// HandleGetNeighbourhoodDiagram renders the entity with the given ref and
+// all entities within the number of hops given by query parameter `depth`,
+// following dependencies in both directions.
+func (c *C4Controller) HandleGetNeighbourhoodDiagram() http.HandlerFunc {
+	e := newPlantUMLExporter()
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ref := chi.URLParam(r, "ref")
+
+		depth, ok := analysis.DepthOf(r)
+		if !ok {
+			message := fmt.Sprintf("invalid depth %v", r.URL.Query().Get("depth"))
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		landscape, err := c.Catalog.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		g := analysis.EntityGraph(landscape)
+		if !g.Contains(ref) {
+			message := fmt.Sprintf("entity %v not found", ref)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return
+		}
+
+		neighbourhood := analysis.Traverse(g, ref, analysis.DirectionBoth, depth)
+
+		relations := relationsBetween(landscape, neighbourhood.Refs())
+		c4Model := NeighbourhoodDiagram(landscape, ref, relations)
+
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLContainer)
+	}
+}
+
+// HandleGetSystemContextDiagram renders the context of a single system,
+// the persons and systems using it and the systems it depends on.
+func (c *C4Controller) HandleGetSystemContextDiagram() http.HandlerFunc {
 	e := newPlantUMLExporter()
-	plantUMLServer := c.Config.PlantUMLServer
 	isProduction := c.Config.IsProduction()
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		name := chi.URLParam(r, "name")
-		c4Model, err := c.Repository.ContainerDiagram(r.Context(), name)
+		c4Model, err := c.Repository.SystemContextDiagram(r.Context(), name)
 		if err != nil {
 			shared.RenderProblemJSON(w, isProduction, err)
 			return
@@ -123,55 +446,279 @@ func (c *C4Controller) HandleGetContainerDiagram() http.HandlerFunc {
 			return
 		}
 
-		// adjust scale
-		scale, _ := strconv.ParseFloat(r.URL.Query().Get("scale"), 64)
-		c4Model.Scale = scale
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLContext)
+	}
+}
+
+// HandleGetComponentDiagram renders the components of a container of a
+// system with the containers and systems they talk to.
+func (c *C4Controller) HandleGetComponentDiagram() http.HandlerFunc {
+	e := newPlantUMLExporter()
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		system := chi.URLParam(r, "system")
+		container := chi.URLParam(r, "container")
+		c4Model, err := c.Repository.ComponentDiagram(r.Context(), system, container)
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
 
-		// adjust image format
-		imageFormat := r.URL.Query().Get("format")
+		if len(c4Model.Components) == 0 {
+			message := fmt.Sprintf("no components of container %v in system %v found", container, system)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return
+		}
 
-		sw := bytes.NewBufferString("")
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLComponent)
+	}
+}
 
-		err = e.ExportToPlantUMLContainer(c4Model, sw)
+// HandleGetAPIDiagram renders an API with the system and container providing
+// it and all containers consuming it grouped by their systems.
+func (c *C4Controller) HandleGetAPIDiagram() http.HandlerFunc {
+	e := newPlantUMLExporter()
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "name")
+		c4Model, err := c.Repository.APIDiagram(r.Context(), name)
 		if err != nil {
 			shared.RenderProblemJSON(w, isProduction, err)
 			return
 		}
 
-		renderPlantUML(
-			w,
-			sw.String(),
-			imageFormat,
-			plantUMLServer,
-			isProduction,
-		)
+		if len(c4Model.APIs) == 0 {
+			message := fmt.Sprintf("api %v not found", name)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return
+		}
+
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLContainer)
 	}
 }
 
-func renderPlantUML(w http.ResponseWriter, plantUMLBody string, imageFormat string, plantUMLServer string, isProduction bool) {
-	outputFormat := "png"
-	if imageFormat == "svg" {
-		outputFormat = "svg"
-	} else if imageFormat == "pdf" {
-		outputFormat = "pdf"
-	} else if imageFormat == "puml" {
-		w.Write([]byte(plantUMLBody))
-		return
+// HandleGetDeploymentDiagram renders the deployment of the containers of a
+// system in the environment given by query parameter `env`, which defaults
+// to `production`.
+func (c *C4Controller) HandleGetDeploymentDiagram() http.HandlerFunc {
+	e := newPlantUMLExporter()
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		system := chi.URLParam(r, "system")
+		env := r.URL.Query().Get("env")
+		if env == "" {
+			env = "production"
+		}
+
+		c4Model, err := c.Repository.DeploymentDiagram(r.Context(), system, env)
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if len(c4Model.DeploymentNodes) == 0 {
+			message := fmt.Sprintf("no deployment of system %v in environment %v found", system, env)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return
+		}
+
+		c.renderModel(w, r, c4Model, e.ExportToPlantUMLDeployment)
 	}
+}
 
-	response, err := http.Post(
-		fmt.Sprintf("%v/%v", plantUMLServer, outputFormat),
-		"text/plain; charset=UTF-8",
-		strings.NewReader(plantUMLBody),
-	)
-	if err != nil {
-		shared.RenderProblemJSON(w, isProduction, err)
+// HandleGetFlows lists the flows of a system.
+func (c *C4Controller) HandleGetFlows() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		flows, err := c.Repository.FindFlows(r.Context(), chi.URLParam(r, "system"))
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		flowsModel := flowsModel{
+			Data: flows,
+		}
+
+		shared.RenderJSON(w, flowsModel)
+	}
+}
+
+// HandlePutFlow creates or replaces a flow of a system of the catalog. Flows
+// with steps referring to entities missing in the catalog are rejected.
+func (c *C4Controller) HandlePutFlow() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var flow Flow
+		err := json.NewDecoder(r.Body).Decode(&flow)
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("invalid flow")).JSONString(), http.StatusBadRequest)
+			return
+		}
+		flow.System = chi.URLParam(r, "system")
+		flow.Name = chi.URLParam(r, "flow")
+
+		landscape, err := c.Catalog.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		known := slices.ContainsFunc(landscape.Systems, func(system catalog.System) bool {
+			return system.Name == flow.System
+		})
+		if !known {
+			message := fmt.Sprintf("system %v not found", flow.System)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return
+		}
+
+		problems := flow.Validate(landscape)
+		if len(problems) > 0 {
+			http.Error(w, problem.New(problem.Title("invalid flow"), problem.Detail(strings.Join(problems, "; "))).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		err = c.Repository.SaveFlow(r.Context(), &flow)
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		shared.RenderJSON(w, flow)
+	}
+}
+
+// HandleDeleteFlow deletes a flow of a system.
+func (c *C4Controller) HandleDeleteFlow() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := c.Repository.DeleteFlow(r.Context(), chi.URLParam(r, "system"), chi.URLParam(r, "flow"))
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// HandleGetFlowDiagram renders a flow of a system as C4 dynamic diagram or,
+// with `style=sequence`, as sequence diagram. Flows referring to entities
+// removed from the catalog since they were saved are rejected.
+func (c *C4Controller) HandleGetFlowDiagram() http.HandlerFunc {
+	e := newPlantUMLExporter()
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		system := chi.URLParam(r, "system")
+		name := chi.URLParam(r, "flow")
+
+		flow, err := c.Repository.FindFlow(r.Context(), system, name)
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		if flow == nil {
+			message := fmt.Sprintf("flow %v of system %v not found", name, system)
+			http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+			return
+		}
+
+		landscape, err := c.Catalog.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		problems := flow.Validate(landscape)
+		if len(problems) > 0 {
+			message := fmt.Sprintf("flow %v of system %v refers to entities missing in the catalog", name, system)
+			http.Error(w, problem.New(problem.Title(message), problem.Detail(strings.Join(problems, "; "))).JSONString(), http.StatusConflict)
+			return
+		}
+
+		c4Model := flow.DynamicDiagram(landscape)
+
+		export := e.ExportToPlantUMLDynamic
+		if r.URL.Query().Get("style") == "sequence" {
+			export = e.ExportToPlantUMLSequence
+		}
+		c.renderModel(w, r, c4Model, export)
+	}
+}
+
+// renderModel exports the model in the format given by query parameter
+// `format` or else negotiated by the `Accept` header, at the scale given by
+// query parameter `scale`. Svg images are rendered natively without the
+// PlantUML server with renderer `native`, given by query parameter
+// `renderer` or the config. The native renderer makes svg the default format
+// and rejects png and pdf.
+func (c *C4Controller) renderModel(
+	w http.ResponseWriter,
+	r *http.Request,
+	c4Model *C4DiagramModel,
+	export func(c4Model *C4DiagramModel, w io.Writer) error,
+) {
+	isProduction := c.Config.IsProduction()
+
+	renderer := r.URL.Query().Get("renderer")
+	if renderer == "" {
+		renderer = c.Config.Renderer
+	}
+
+	// the native renderer renders svg images only, so png and pdf images
+	// rendered by the PlantUML server are not offered
+	exporters := c.Exporters
+	if renderer == "native" {
+		exporters = exporters.Without("png", "pdf")
+	}
+
+	format, ok := exporters.Negotiate(r)
+	if !ok {
+		var names []string
+		for _, f := range exporters.Formats() {
+			names = append(names, f.Name)
+		}
+		detail := problem.Detail(fmt.Sprintf("supported formats are %v", strings.Join(names, ", ")))
+
+		if name := r.URL.Query().Get("format"); name != "" {
+			message := fmt.Sprintf("unknown format %v", name)
+			if _, known := c.Exporters.FormatOf(name); known {
+				message = fmt.Sprintf("format %v is not supported by renderer %v", name, renderer)
+			}
+			http.Error(w, problem.New(problem.Title(message), detail).JSONString(), http.StatusBadRequest)
+			return
+		}
+		message := fmt.Sprintf("no format acceptable for %v", r.Header.Get("Accept"))
+		http.Error(w, problem.New(problem.Title(message), detail).JSONString(), http.StatusNotAcceptable)
 		return
 	}
 
-	_, err = io.Copy(w, response.Body)
+	// adjust scale
+	scale, _ := strconv.ParseFloat(r.URL.Query().Get("scale"), 64)
+	c4Model.Scale = scale
+
+	sw := bytes.NewBufferString("")
+	err := exporters.Exporter(format).Export(DiagramExport{
+		Model:    c4Model,
+		PlantUML: export,
+		Renderer: renderer,
+	}, sw)
 	if err != nil {
 		shared.RenderProblemJSON(w, isProduction, err)
 		return
 	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", diagramFileName(r, format)))
+	w.Header().Add("Vary", "Accept")
+	w.Write(sw.Bytes())
 }
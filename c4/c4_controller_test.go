@@ -0,0 +1,55 @@
+package c4
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.io/remast/c4stage/catalog"
+	"github.io/remast/c4stage/shared"
+)
+
+type catalogRepository struct {
+	catalog.CatalogRepository
+	landscape *catalog.Landscape
+	snapshots []string
+}
+
+func (r *catalogRepository) FindLandscape(ctx context.Context) (*catalog.Landscape, error) {
+	return r.landscape, nil
+}
+
+func (r *catalogRepository) FindSnapshot(ctx context.Context, id string) (*catalog.Snapshot, error) {
+	for _, snapshot := range r.snapshots {
+		if snapshot == id {
+			return &catalog.Snapshot{ID: id}, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestFromSnapshot(t *testing.T) {
+	is := is.New(t)
+	c := &C4Controller{Config: &shared.Config{}, Catalog: &catalogRepository{snapshots: []string{"s1"}}}
+
+	w := httptest.NewRecorder()
+	from, ok := c.fromSnapshot(w, httptest.NewRequest("GET", "/c4/diff/context?from=s1", nil))
+	is.True(ok)
+	is.Equal(from, "s1")
+
+	w = httptest.NewRecorder()
+	_, ok = c.fromSnapshot(w, httptest.NewRequest("GET", "/c4/diff/context?from="+shared.LiveSnapshot, nil))
+	is.True(ok)
+
+	w = httptest.NewRecorder()
+	_, ok = c.fromSnapshot(w, httptest.NewRequest("GET", "/c4/diff/context?from=unknown", nil))
+	is.True(!ok)
+	is.Equal(w.Code, http.StatusNotFound)
+
+	w = httptest.NewRecorder()
+	_, ok = c.fromSnapshot(w, httptest.NewRequest("GET", "/c4/diff/context", nil))
+	is.True(!ok)
+	is.Equal(w.Code, http.StatusBadRequest)
+}
@@ -0,0 +1,112 @@
+package c4
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DeploymentNode is infrastructure containers are deployed on, like a
+// Kubernetes cluster. Deployment nodes are nested in their parent nodes.
+type DeploymentNode struct {
+	ID          string
+	Label       string
+	Title       string
+	Technology  string
+	Description string
+	Nodes       []*DeploymentNode
+	Containers  []*Container
+	Tags        []string
+}
+
+func (n DeploymentNode) AsTags() string {
+	return asTags(n.Tags)
+}
+
+// ResolveDeploymentPath splits the path of deployment nodes like
+// `eks-cluster/orders` and prepends the parents of the first node, as nodes
+// may be placed in an environment themselves.
+func ResolveDeploymentPath(path string, parents map[string]string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.TrimSpace(segment)
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	for len(segments) > 0 {
+		parentPath, ok := parents[segments[0]]
+		if !ok || parentPath == "" {
+			break
+		}
+
+		var parentSegments []string
+		for _, segment := range strings.Split(parentPath, "/") {
+			segment = strings.TrimSpace(segment)
+			if segment != "" {
+				parentSegments = append(parentSegments, segment)
+			}
+		}
+
+		// stop at cyclic parents
+		if slices.ContainsFunc(parentSegments, func(segment string) bool {
+			return slices.Contains(segments, segment)
+		}) {
+			break
+		}
+		segments = append(parentSegments, segments...)
+	}
+
+	return segments
+}
+
+// Deploy places the container in the nested deployment nodes with the given
+// labels. Missing nodes are created, taking title, technology and
+// description from the known nodes with the same label.
+func (c4Model *C4DiagramModel) Deploy(container *Container, path []string, known map[string]*DeploymentNode) {
+	if len(path) == 0 {
+		return
+	}
+
+	nodes := &c4Model.DeploymentNodes
+	var node *DeploymentNode
+	for i, label := range path {
+		node = nil
+		for _, n := range *nodes {
+			if n.Label == label {
+				node = n
+				break
+			}
+		}
+
+		if node == nil {
+			node = &DeploymentNode{
+				ID:    deploymentNodeID(path[:i+1]),
+				Label: label,
+				Title: label,
+			}
+			if k, ok := known[label]; ok {
+				node.Title = k.Title
+				node.Technology = k.Technology
+				node.Description = k.Description
+				node.Tags = slices.Clone(k.Tags)
+			}
+			*nodes = append(*nodes, node)
+		}
+
+		nodes = &node.Nodes
+	}
+
+	node.Containers = append(node.Containers, container)
+}
+
+func deploymentNodeID(path []string) string {
+	id := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, strings.Join(path, "_"))
+	return "node_" + id
+}
@@ -0,0 +1,74 @@
+package c4
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestResolveDeploymentPath(t *testing.T) {
+	is := is.New(t)
+
+	parents := map[string]string{
+		"eks-cluster": "aws-prod",
+	}
+
+	path := ResolveDeploymentPath("eks-cluster/ orders /", parents)
+
+	is.Equal(path, []string{"aws-prod", "eks-cluster", "orders"})
+}
+
+func TestResolveDeploymentPathWithCycle(t *testing.T) {
+	is := is.New(t)
+
+	parents := map[string]string{
+		"a": "b",
+		"b": "a",
+	}
+
+	path := ResolveDeploymentPath("a", parents)
+
+	is.Equal(path, []string{"b", "a"})
+}
+
+func TestDeployNestsNodes(t *testing.T) {
+	is := is.New(t)
+
+	known := map[string]*DeploymentNode{
+		"eks-cluster": {Label: "eks-cluster", Title: "EKS Cluster", Technology: "kubernetes-cluster"},
+	}
+
+	m := &C4DiagramModel{}
+	m.Deploy(&Container{ID: "c1", Label: "orders-api"}, []string{"aws-prod", "eks-cluster"}, known)
+	m.Deploy(&Container{ID: "c2", Label: "orders-db"}, []string{"aws-prod", "rds"}, known)
+
+	is.Equal(len(m.DeploymentNodes), 1)
+	is.Equal(m.DeploymentNodes[0].ID, "node_aws_prod")
+
+	nodes := m.DeploymentNodes[0].Nodes
+	is.Equal(len(nodes), 2)
+	is.Equal(nodes[0].Title, "EKS Cluster")
+	is.Equal(nodes[0].Technology, "kubernetes-cluster")
+	is.Equal(nodes[0].Containers[0].Label, "orders-api")
+	is.Equal(nodes[1].Title, "rds")
+}
+
+func TestExportToPlantUMLDeployment(t *testing.T) {
+	is := is.New(t)
+	e := newPlantUMLExporter()
+
+	m := &C4DiagramModel{}
+	m.Deploy(&Container{ID: "c1", Label: "orders-api", Title: "Orders API"}, []string{"aws-prod", "eks-cluster"}, nil)
+
+	sw := bytes.NewBufferString("")
+	err := e.ExportToPlantUMLDeployment(m, sw)
+	puml := sw.String()
+
+	is.NoErr(err)
+	is.True(strings.Contains(puml, "!include <C4/C4_Deployment>"))
+	is.True(strings.Contains(puml, `Deployment_Node(node_aws_prod, "aws-prod"`))
+	is.True(strings.Contains(puml, `Deployment_Node(node_aws_prod_eks_cluster, "eks-cluster"`))
+	is.True(strings.Contains(puml, `Container(c1, "Orders API"`))
+}
@@ -0,0 +1,181 @@
+package c4
+
+import (
+	"slices"
+)
+
+// DiffModels merges the models from and to into one model, tagging elements
+// and relations as added, removed or changed. Elements are matched by their
+// label as the ids of elements differ between snapshots.
+func DiffModels(from *C4DiagramModel, to *C4DiagramModel) *C4DiagramModel {
+	diff := &C4DiagramModel{
+		Scale: to.Scale,
+	}
+
+	// ids of the merged elements by element key
+	ids := make(map[string]string)
+	fromKeys := make(map[string]string)
+	toKeys := make(map[string]string)
+
+	fromSystems := from.allSystems()
+	for _, system := range to.allSystems() {
+		key := "system:" + system.Label
+		toKeys[system.ID] = key
+
+		merged := cloneSystem(system)
+		fromSystem := findSystemByLabel(fromSystems, system.Label)
+		if fromSystem == nil {
+			merged.AddTag("added")
+		} else if systemChanged(fromSystem, system) {
+			merged.AddTag("changed")
+		}
+
+		ids[key] = merged.ID
+		diff.AddSystem(merged)
+	}
+	for _, system := range fromSystems {
+		key := "system:" + system.Label
+		fromKeys[system.ID] = key
+		if _, ok := ids[key]; ok {
+			continue
+		}
+
+		merged := cloneSystem(system)
+		merged.AddTag("removed")
+
+		ids[key] = merged.ID
+		diff.AddSystem(merged)
+	}
+
+	for _, container := range to.Containers {
+		key := "component:" + container.Label
+		toKeys[container.ID] = key
+
+		merged := cloneContainer(container)
+		fromContainer := findContainerByLabel(from.Containers, container.Label)
+		if fromContainer == nil {
+			merged.AddTag("added")
+		} else if containerChanged(fromContainer, container) {
+			merged.AddTag("changed")
+		}
+
+		ids[key] = merged.ID
+		diff.AddContainer(merged)
+	}
+	for _, container := range from.Containers {
+		key := "component:" + container.Label
+		fromKeys[container.ID] = key
+		if _, ok := ids[key]; ok {
+			continue
+		}
+
+		merged := cloneContainer(container)
+		merged.AddTag("removed")
+
+		ids[key] = merged.ID
+		diff.AddContainer(merged)
+	}
+
+	fromRelations := relationsByKey(from.Relations, fromKeys)
+	toRelations := relationsByKey(to.Relations, toKeys)
+
+	for _, relation := range to.Relations {
+		sourceKey, targetKey := toKeys[relation.SourceID], toKeys[relation.TargetID]
+		if sourceKey == "" || targetKey == "" {
+			continue
+		}
+
+		merged := relation
+		merged.Tags = slices.Clone(relation.Tags)
+		merged.SourceID = ids[sourceKey]
+		merged.TargetID = ids[targetKey]
+		fromRelation, ok := fromRelations[sourceKey+"->"+targetKey]
+		if !ok {
+			merged.AddTag("added")
+		} else if relationChanged(fromRelation, relation) {
+			merged.AddTag("changed")
+		}
+		diff.AddRelation(merged)
+	}
+	for _, relation := range from.Relations {
+		sourceKey, targetKey := fromKeys[relation.SourceID], fromKeys[relation.TargetID]
+		if sourceKey == "" || targetKey == "" {
+			continue
+		}
+		if _, ok := toRelations[sourceKey+"->"+targetKey]; ok {
+			continue
+		}
+
+		merged := relation
+		merged.Tags = slices.Clone(relation.Tags)
+		merged.SourceID = ids[sourceKey]
+		merged.TargetID = ids[targetKey]
+		merged.AddTag("removed")
+		diff.AddRelation(merged)
+	}
+
+	diff.PostProcess()
+
+	return diff
+}
+
+func relationsByKey(relations []Relation, keys map[string]string) map[string]Relation {
+	relationsByKey := make(map[string]Relation)
+	for _, relation := range relations {
+		relationsByKey[keys[relation.SourceID]+"->"+keys[relation.TargetID]] = relation
+	}
+	return relationsByKey
+}
+
+func findSystemByLabel(systems []*System, label string) *System {
+	for _, system := range systems {
+		if system.Label == label {
+			return system
+		}
+	}
+	return nil
+}
+
+func findContainerByLabel(containers []*Container, label string) *Container {
+	for _, container := range containers {
+		if container.Label == label {
+			return container
+		}
+	}
+	return nil
+}
+
+func cloneSystem(system *System) *System {
+	clone := *system
+	clone.Containers = nil
+	clone.Tags = slices.Clone(system.Tags)
+	return &clone
+}
+
+func cloneContainer(container *Container) *Container {
+	clone := *container
+	clone.Tags = slices.Clone(container.Tags)
+	return &clone
+}
+
+func systemChanged(from *System, to *System) bool {
+	return from.Title != to.Title ||
+		from.Description != to.Description ||
+		from.Type != to.Type ||
+		!slices.Equal(from.Tags, to.Tags)
+}
+
+func relationChanged(from Relation, to Relation) bool {
+	return from.Label != to.Label ||
+		from.Technology != to.Technology ||
+		!slices.Equal(from.Usages, to.Usages)
+}
+
+func containerChanged(from *Container, to *Container) bool {
+	return from.Title != to.Title ||
+		from.Description != to.Description ||
+		from.Type != to.Type ||
+		from.Technology != to.Technology ||
+		from.System != to.System ||
+		!slices.Equal(from.Tags, to.Tags)
+}
@@ -0,0 +1,69 @@
+package c4
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDiffModelsWithAddedRemovedAndChanged(t *testing.T) {
+	is := is.New(t)
+
+	from := &C4DiagramModel{}
+	from.AddSystem(&System{ID: "1", Label: "shop", Title: "Shop"})
+	from.AddSystem(&System{ID: "2", Label: "legacy", Title: "Legacy"})
+	from.AddRelation(Relation{SourceID: "1", TargetID: "2"})
+
+	to := &C4DiagramModel{}
+	to.AddSystem(&System{ID: "10", Label: "shop", Title: "Web Shop"})
+	to.AddSystem(&System{ID: "11", Label: "payment", Title: "Payment"})
+	to.AddRelation(Relation{SourceID: "10", TargetID: "11"})
+
+	diff := DiffModels(from, to)
+
+	is.Equal(len(diff.Systems), 3)
+	is.Equal(diff.Systems[0].AsTags(), "changed")
+	is.Equal(diff.Systems[1].AsTags(), "added")
+	is.Equal(diff.Systems[2].AsTags(), "removed")
+
+	is.Equal(len(diff.Relations), 2)
+	is.Equal(diff.Relations[0], Relation{SourceID: "10", TargetID: "11", Tags: []string{"added"}})
+	is.Equal(diff.Relations[1], Relation{SourceID: "10", TargetID: "2", Tags: []string{"removed"}})
+}
+
+func TestDiffModelsWithChangedRelation(t *testing.T) {
+	is := is.New(t)
+
+	from := &C4DiagramModel{}
+	from.AddSystem(&System{ID: "1", Label: "shop"})
+	from.AddSystem(&System{ID: "2", Label: "payment"})
+	from.AddRelation(Relation{SourceID: "1", TargetID: "2", Label: "uses", Technology: "REST/JSON"})
+
+	to := &C4DiagramModel{}
+	to.AddSystem(&System{ID: "10", Label: "shop"})
+	to.AddSystem(&System{ID: "11", Label: "payment"})
+	to.AddRelation(Relation{SourceID: "10", TargetID: "11", Label: "uses", Technology: "gRPC"})
+
+	diff := DiffModels(from, to)
+
+	is.Equal(diff.Systems[0].AsTags(), "")
+	is.Equal(len(diff.Relations), 1)
+	is.Equal(diff.Relations[0].AsTags(), "changed")
+	is.Equal(diff.Relations[0].Technology, "gRPC")
+}
+
+func TestDiffModelsWithoutChanges(t *testing.T) {
+	is := is.New(t)
+
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "1", Label: "shop"})
+	m.AddContainer(&Container{ID: "2", Label: "shop-ui", System: "shop"})
+	m.AddRelation(Relation{SourceID: "2", TargetID: "1"})
+
+	diff := DiffModels(m, m)
+
+	is.Equal(diff.Systems[0].AsTags(), "")
+	is.Equal(diff.Containers[0].AsTags(), "")
+	is.Equal(diff.Relations[0].AsTags(), "")
+	is.Equal(len(diff.Systems[0].Containers), 1)
+}
@@ -11,6 +11,9 @@ type C4DiagramModel struct {
 	Systems         []*System
 	ExternalSystems []*System
 	Containers      []*Container
+	Components      []*Component
+	APIs            []*API
+	DeploymentNodes []*DeploymentNode
 	Relations       []Relation
 	Persons         []*System
 	Scale           float64
@@ -24,6 +27,7 @@ type System struct {
 	Type        string
 	Technology  string
 	Containers  []*Container
+	APIs        []*API
 	Tags        []string
 }
 
@@ -35,6 +39,32 @@ type Container struct {
 	Technology  string
 	Type        string
 	System      string
+	Components  []*Component
+	Tags        []string
+}
+
+// Component is a component within a container, the element of C4 component
+// diagrams.
+type Component struct {
+	ID          string
+	Label       string
+	Title       string
+	Description string
+	Technology  string
+	Type        string
+	Container   string
+	Tags        []string
+}
+
+// API is an API provided by a system or container, drawn as element of its
+// own with the type of the API like openapi, grpc or asyncapi.
+type API struct {
+	ID          string
+	Label       string
+	Title       string
+	Description string
+	Type        string
+	System      string
 	Tags        []string
 }
 
@@ -45,21 +75,78 @@ type Relation struct {
 	Title       string
 	Description string
 	Technology  string
+	Usages      []Usage
+	Tags        []string
+}
+
+// Usage is the use of an API a relation goes through, labelled like
+// `places orders via orders-api`.
+type Usage struct {
+	API        string
+	Label      string
+	Technology string
 }
 
 type C4Repository interface {
+	ComponentDiagram(
+		ctx context.Context,
+		system string,
+		container string,
+	) (*C4DiagramModel, error)
+
 	ContainerDiagram(
 		ctx context.Context,
 		name string,
+		filter DiagramFilter,
 	) (*C4DiagramModel, error)
 
 	SystemLandscapeContainerDiagram(
 		ctx context.Context,
+		filter DiagramFilter,
 	) (*C4DiagramModel, error)
 
 	SystemLandscapeDiagram(
 		ctx context.Context,
+		filter DiagramFilter,
+	) (*C4DiagramModel, error)
+
+	SystemContextDiagram(
+		ctx context.Context,
+		name string,
 	) (*C4DiagramModel, error)
+
+	APIDiagram(
+		ctx context.Context,
+		name string,
+	) (*C4DiagramModel, error)
+
+	DeploymentDiagram(
+		ctx context.Context,
+		system string,
+		env string,
+	) (*C4DiagramModel, error)
+
+	FindFlows(
+		ctx context.Context,
+		system string,
+	) ([]Flow, error)
+
+	FindFlow(
+		ctx context.Context,
+		system string,
+		name string,
+	) (*Flow, error)
+
+	SaveFlow(
+		ctx context.Context,
+		flow *Flow,
+	) error
+
+	DeleteFlow(
+		ctx context.Context,
+		system string,
+		name string,
+	) error
 }
 
 var spriteWhitelist = map[string]string{
@@ -72,7 +159,17 @@ var spriteWhitelist = map[string]string{
 	"angular":    "angular",
 	"oracle":     "oracle_original",
 }
-var tagWhitelist = []string{"deprecated", "experimental"}
+var tagWhitelist = []string{
+	"deprecated", "experimental",
+	"added", "removed", "changed",
+	"cycle",
+	"violation",
+	"hop0", "hop1", "hop2", "hop3",
+	"path",
+	"inbound", "outbound",
+	"focus",
+	"api",
+}
 
 func (m C4DiagramModel) IsEmpty() bool {
 	return len(m.Systems) == 0 && len(m.Containers) == 0 && len(m.ExternalSystems) == 0
@@ -86,6 +183,11 @@ func (s System) IsPerson() bool {
 	return s.Type == "person"
 }
 
+// Ref returns the entity ref of the system in the catalog.
+func (s System) Ref() string {
+	return "system:" + s.Label
+}
+
 func (s *System) AddTag(toAdd string) {
 	s.Tags = append(s.Tags, toAdd)
 }
@@ -98,14 +200,12 @@ func (m *C4DiagramModel) ScaleFormatted() string {
 }
 
 func (s System) AsTags() string {
-	var tags []string
-	for _, tag := range s.Tags {
-		if !slices.Contains(tagWhitelist, tag) {
-			continue
-		}
-		tags = append(tags, tag)
-	}
-	return strings.Join(tags, ",")
+	return asTags(s.Tags)
+}
+
+// Ref returns the entity ref of the container in the catalog.
+func (c Container) Ref() string {
+	return "component:" + c.Label
 }
 
 func (c *Container) AddTag(toAdd string) {
@@ -129,11 +229,92 @@ func (c4Model *C4DiagramModel) PostProcess() {
 			}
 		}
 	}
+
+	// Add all APIs to the System providing them
+	for _, system := range c4Model.Systems {
+		for _, api := range c4Model.APIs {
+			if api.System == system.Label {
+				system.APIs = append(system.APIs, api)
+			}
+		}
+	}
+
+	// Add all Components to their Container
+	for _, container := range c4Model.Containers {
+		for _, component := range c4Model.Components {
+			if component.Container == container.Label {
+				container.Components = append(container.Components, component)
+			}
+		}
+	}
+}
+
+func (c4Model *C4DiagramModel) AddComponent(toAdd *Component) {
+	for _, component := range c4Model.Components {
+		if component.ID == toAdd.ID {
+			return
+		}
+	}
+	c4Model.Components = append(c4Model.Components, toAdd)
+}
+
+// HasElement checks whether the model contains an element with the given id.
+func (c4Model *C4DiagramModel) HasElement(id string) bool {
+	for _, system := range c4Model.allSystems() {
+		if system.ID == id {
+			return true
+		}
+	}
+	for _, container := range c4Model.Containers {
+		if container.ID == id {
+			return true
+		}
+	}
+	for _, component := range c4Model.Components {
+		if component.ID == id {
+			return true
+		}
+	}
+	for _, api := range c4Model.APIs {
+		if api.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (c4Model *C4DiagramModel) AddAPI(toAdd *API) {
+	for _, api := range c4Model.APIs {
+		if api.ID == toAdd.ID {
+			return
+		}
+	}
+	c4Model.APIs = append(c4Model.APIs, toAdd)
+}
+
+// StandaloneAPIs returns all APIs not provided by a system drawn with its
+// boundary.
+func (c4Model *C4DiagramModel) StandaloneAPIs() []*API {
+	var apis []*API
+	for _, api := range c4Model.APIs {
+		provided := slices.ContainsFunc(c4Model.Systems, func(system *System) bool {
+			return system.Label == api.System
+		})
+		if !provided {
+			apis = append(apis, api)
+		}
+	}
+	return apis
 }
 
+// AddRelation adds the relation unless the elements are related already,
+// in which case the uses of APIs of both relations are merged.
 func (c4Model *C4DiagramModel) AddRelation(toAdd Relation) {
-	for _, relation := range c4Model.Relations {
+	for i, relation := range c4Model.Relations {
 		if relation.SourceID == toAdd.SourceID && relation.TargetID == toAdd.TargetID {
+			for _, usage := range toAdd.Usages {
+				c4Model.Relations[i].AddUsage(usage)
+			}
 			return
 		}
 	}
@@ -190,6 +371,74 @@ func (c4Model *C4DiagramModel) AddContainer(toAdd *Container) {
 	c4Model.Containers = append(c4Model.Containers, toAdd)
 }
 
+// allSystems returns all systems, external systems and persons.
+func (c4Model *C4DiagramModel) allSystems() []*System {
+	var systems []*System
+	systems = append(systems, c4Model.Systems...)
+	systems = append(systems, c4Model.ExternalSystems...)
+	systems = append(systems, c4Model.Persons...)
+	return systems
+}
+
+// TagElement adds the tag to the element with the given entity ref.
+func (c4Model *C4DiagramModel) TagElement(ref string, tag string) {
+	for _, system := range c4Model.allSystems() {
+		if system.Ref() == ref {
+			system.AddTag(tag)
+		}
+	}
+	for _, container := range c4Model.Containers {
+		if container.Ref() == ref {
+			container.AddTag(tag)
+		}
+	}
+	for _, api := range c4Model.APIs {
+		if api.Ref() == ref {
+			api.AddTag(tag)
+		}
+	}
+}
+
+// TagRelation adds the tag to the relation between the elements with the
+// given entity refs.
+func (c4Model *C4DiagramModel) TagRelation(sourceRef string, targetRef string, tag string) {
+	sourceID := c4Model.idOfRef(sourceRef)
+	targetID := c4Model.idOfRef(targetRef)
+	for i, relation := range c4Model.Relations {
+		if relation.SourceID == sourceID && relation.TargetID == targetID {
+			c4Model.Relations[i].AddTag(tag)
+		}
+	}
+}
+
+func (c4Model *C4DiagramModel) hasRelation(sourceID string, targetID string) bool {
+	for _, relation := range c4Model.Relations {
+		if relation.SourceID == sourceID && relation.TargetID == targetID {
+			return true
+		}
+	}
+	return false
+}
+
+func (c4Model *C4DiagramModel) idOfRef(ref string) string {
+	for _, system := range c4Model.allSystems() {
+		if system.Ref() == ref {
+			return system.ID
+		}
+	}
+	for _, container := range c4Model.Containers {
+		if container.Ref() == ref {
+			return container.ID
+		}
+	}
+	for _, api := range c4Model.APIs {
+		if api.Ref() == ref {
+			return api.ID
+		}
+	}
+	return ""
+}
+
 func (c Container) Sprite() string {
 	for _, tag := range c.Tags {
 		spriteName, ok := spriteWhitelist[tag]
@@ -201,14 +450,80 @@ func (c Container) Sprite() string {
 }
 
 func (c Container) AsTags() string {
+	return asTags(c.Tags)
+}
+
+// Ref returns the entity ref of the component in the catalog.
+func (c Component) Ref() string {
+	return "component:" + c.Label
+}
+
+func (c *Component) AddTag(toAdd string) {
+	c.Tags = append(c.Tags, toAdd)
+}
+
+func (c Component) IsDatabase() bool {
+	return c.Type == "database"
+}
+
+func (c Component) AsTags() string {
+	return asTags(c.Tags)
+}
+
+// Ref returns the entity ref of the API in the catalog.
+func (a API) Ref() string {
+	return "api:" + a.Label
+}
+
+func (a *API) AddTag(toAdd string) {
+	a.Tags = append(a.Tags, toAdd)
+}
+
+// AsTags returns the tags of the API, which always include `api`.
+func (a API) AsTags() string {
+	return asTags(append([]string{"api"}, a.Tags...))
+}
+
+// AddUsage adds the use of an API to the relation. The label and technology
+// of the relation are merged from all uses ordered by API.
+func (r *Relation) AddUsage(toAdd Usage) {
+	if slices.ContainsFunc(r.Usages, func(usage Usage) bool { return usage.API == toAdd.API }) {
+		return
+	}
+	r.Usages = append(r.Usages, toAdd)
+	slices.SortFunc(r.Usages, func(a Usage, b Usage) int {
+		return strings.Compare(a.API, b.API)
+	})
+
+	var labels, technologies []string
+	for _, usage := range r.Usages {
+		labels = append(labels, usage.Label)
+		if usage.Technology != "" && !slices.Contains(technologies, usage.Technology) {
+			technologies = append(technologies, usage.Technology)
+		}
+	}
+	r.Label = strings.Join(labels, ", ")
+	r.Technology = strings.Join(technologies, ", ")
+}
+
+func (r *Relation) AddTag(toAdd string) {
+	r.Tags = append(r.Tags, toAdd)
+}
+
+func (r Relation) AsTags() string {
+	return asTags(r.Tags)
+}
+
+// asTags joins all whitelisted tags the way PlantUML expects multiple tags.
+func asTags(elementTags []string) string {
 	var tags []string
-	for _, tag := range c.Tags {
-		if !slices.Contains(tagWhitelist, tag) {
+	for _, tag := range elementTags {
+		if !slices.Contains(tagWhitelist, tag) || slices.Contains(tags, tag) {
 			continue
 		}
 		tags = append(tags, tag)
 	}
-	return strings.Join(tags, ",")
+	return strings.Join(tags, "+")
 }
 
 func AsID(elementID string) string {
@@ -217,12 +532,84 @@ func AsID(elementID string) string {
 	return res
 }
 
+// AsHopTag returns the tag of an element the given number of hops away,
+// all elements three or more hops away share one tag.
+func AsHopTag(distance int) string {
+	return fmt.Sprintf("hop%d", min(distance, 3))
+}
+
+// tagElementColors are the background colors of elements by tag, for
+// formats without tag styles like Mermaid, draw.io and SVG.
+var tagElementColors = map[string]string{
+	"experimental": "DeepSkyBlue",
+	"deprecated":   "DarkCyan",
+	"added":        "Green",
+	"removed":      "Red",
+	"changed":      "Orange",
+	"cycle":        "Crimson",
+	"hop0":         "Crimson",
+	"hop1":         "OrangeRed",
+	"hop2":         "DarkOrange",
+	"hop3":         "Goldenrod",
+	"path":         "Crimson",
+	"focus":        "Crimson",
+	"api":          "SlateGray",
+}
+
+// tagRelationColors are the line colors of relations by tag.
+var tagRelationColors = map[string]string{
+	"added":     "Green",
+	"removed":   "Red",
+	"changed":   "Orange",
+	"cycle":     "Crimson",
+	"path":      "Crimson",
+	"inbound":   "SteelBlue",
+	"outbound":  "DarkOrange",
+	"violation": "Red",
+}
+
+// tagColor returns the color of the first of the tags with a color.
+func tagColor(colors map[string]string, tags []string) string {
+	for _, tag := range tags {
+		if color, ok := colors[tag]; ok {
+			return color
+		}
+	}
+	return ""
+}
+
+// apiTechnologies maps the types of APIs to the technology they are used with.
+var apiTechnologies = map[string]string{
+	"openapi":  "REST/JSON",
+	"grpc":     "gRPC",
+	"graphql":  "GraphQL",
+	"asyncapi": "Messaging",
+}
+
+// AsTechnology returns the technology an API of the given type is used with.
+func AsTechnology(apiType string) string {
+	return apiTechnologies[strings.ToLower(apiType)]
+}
+
+// AsUsage labels the use of the API with the given name by its description
+// like `places orders via orders-api`, or `uses orders-api` without one.
+func AsUsage(apiName string, description string) string {
+	if description == "" {
+		return "uses " + apiName
+	}
+	return description + " via " + apiName
+}
+
 func AsRelation(relation string) string {
 	switch relation {
 	case "DEPENDS_ON":
 		return "depends on"
 	case "CONTAINS":
 		return "contains"
+	case "CONSUMES":
+		return "uses"
+	case "PROVIDES":
+		return "provides"
 	default:
 		return relation
 	}
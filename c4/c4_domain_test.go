@@ -93,7 +93,6 @@ func TestAsTagsWithNonWhitelistedTag(t *testing.T) {
 	is.Equal(c.AsTags(), "")
 }
 
-
 func TestC4ModelScaleFormattedWithUnsetScale(t *testing.T) {
 	is := is.New(t)
 
@@ -109,4 +108,50 @@ func TestC4ModelScaleFormattedWithScale(t *testing.T) {
 	m.Scale = .3
 
 	is.Equal(m.ScaleFormatted(), "0.30")
-}
\ No newline at end of file
+}
+
+func TestAsTagsWithMultipleWhitelistedTags(t *testing.T) {
+	is := is.New(t)
+
+	c := &Container{
+		Tags: []string{"deprecated", "java", "added"},
+	}
+
+	is.Equal(c.AsTags(), "deprecated+added")
+}
+
+func TestAsHopTag(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(AsHopTag(0), "hop0")
+	is.Equal(AsHopTag(2), "hop2")
+	is.Equal(AsHopTag(7), "hop3")
+}
+
+func TestAddRelationMergesUsages(t *testing.T) {
+	is := is.New(t)
+
+	m := &C4DiagramModel{}
+	m.AddRelation(Relation{SourceID: "s1", TargetID: "s2", Label: "depends on"})
+	for _, usage := range []Usage{
+		{API: "stock-api", Label: "uses stock-api", Technology: "gRPC"},
+		{API: "orders-api", Label: "places orders via orders-api", Technology: "REST/JSON"},
+		{API: "billing-api", Label: "uses billing-api", Technology: "REST/JSON"},
+		{API: "stock-api", Label: "uses stock-api", Technology: "gRPC"},
+	} {
+		relation := Relation{SourceID: "s1", TargetID: "s2"}
+		relation.AddUsage(usage)
+		m.AddRelation(relation)
+	}
+
+	is.Equal(len(m.Relations), 1)
+	is.Equal(m.Relations[0].Label, "uses billing-api, places orders via orders-api, uses stock-api")
+	is.Equal(m.Relations[0].Technology, "REST/JSON, gRPC")
+}
+
+func TestAsUsage(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(AsUsage("orders-api", ""), "uses orders-api")
+	is.Equal(AsUsage("orders-api", "places orders"), "places orders via orders-api")
+}
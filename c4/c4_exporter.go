@@ -0,0 +1,269 @@
+package c4
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.io/remast/c4stage/shared"
+)
+
+// DiagramExport is a model to export, with the PlantUML export of its type
+// of diagram for the formats rendered from PlantUML.
+type DiagramExport struct {
+	Model    *C4DiagramModel
+	PlantUML func(c4Model *C4DiagramModel, w io.Writer) error
+	// Renderer renders svg images, either plantuml or native.
+	Renderer string
+}
+
+// DiagramExporter exports diagrams in one format.
+type DiagramExporter interface {
+	Export(export DiagramExport, w io.Writer) error
+}
+
+// DiagramExporterFunc is a function used as DiagramExporter.
+type DiagramExporterFunc func(export DiagramExport, w io.Writer) error
+
+func (f DiagramExporterFunc) Export(export DiagramExport, w io.Writer) error {
+	return f(export, w)
+}
+
+// DiagramFormat is a format of diagrams, selected by name with query
+// parameter `format` or by media type with the `Accept` header.
+type DiagramFormat struct {
+	Name      string
+	MediaType string
+	Extension string
+}
+
+// ContentType is the media type, with the charset for text formats.
+func (f DiagramFormat) ContentType() string {
+	if strings.HasPrefix(f.MediaType, "text/") || strings.HasSuffix(f.MediaType, "+xml") {
+		return f.MediaType + "; charset=utf-8"
+	}
+	return f.MediaType
+}
+
+// ExporterRegistry holds the exporters of diagrams by media type. The first
+// registered format is the default.
+type ExporterRegistry struct {
+	formats   []DiagramFormat
+	exporters map[string]DiagramExporter
+}
+
+func NewExporterRegistry() *ExporterRegistry {
+	return &ExporterRegistry{
+		exporters: make(map[string]DiagramExporter),
+	}
+}
+
+// NewDefaultExporterRegistry registers exporters for png, svg, pdf, puml,
+// mermaid, d2, dot and drawio, with png as default.
+func NewDefaultExporterRegistry(config *shared.Config) *ExporterRegistry {
+	r := NewExporterRegistry()
+
+	r.Register(DiagramFormat{Name: "png", MediaType: "image/png", Extension: "png"}, plantUMLServerExporter(config.PlantUMLServer, "png"))
+	r.Register(DiagramFormat{Name: "svg", MediaType: "image/svg+xml", Extension: "svg"}, svgExporterOf(config.PlantUMLServer))
+	r.Register(DiagramFormat{Name: "pdf", MediaType: "application/pdf", Extension: "pdf"}, plantUMLServerExporter(config.PlantUMLServer, "pdf"))
+	r.Register(DiagramFormat{Name: "puml", MediaType: "text/x-plantuml", Extension: "puml"}, DiagramExporterFunc(func(export DiagramExport, w io.Writer) error {
+		return export.PlantUML(export.Model, w)
+	}))
+	r.Register(DiagramFormat{Name: "mermaid", MediaType: "text/vnd.mermaid", Extension: "mmd"}, modelExporter(newMermaidExporter().ExportToMermaid))
+	r.Register(DiagramFormat{Name: "d2", MediaType: "text/vnd.d2", Extension: "d2"}, modelExporter(newD2Exporter().ExportToD2))
+	r.Register(DiagramFormat{Name: "dot", MediaType: "text/vnd.graphviz", Extension: "dot"}, modelExporter(newDotExporter().ExportToDot))
+	r.Register(DiagramFormat{Name: "drawio", MediaType: "application/vnd.jgraph.mxfile", Extension: "drawio"}, modelExporter(newDrawioExporter().ExportToDrawio))
+
+	return r
+}
+
+// Register registers the exporter for the format, replacing any exporter
+// registered for the media type before.
+func (r *ExporterRegistry) Register(format DiagramFormat, exporter DiagramExporter) {
+	if _, ok := r.exporters[format.MediaType]; !ok {
+		r.formats = append(r.formats, format)
+	}
+	r.exporters[format.MediaType] = exporter
+}
+
+func (r *ExporterRegistry) Formats() []DiagramFormat {
+	return r.formats
+}
+
+func (r *ExporterRegistry) Exporter(format DiagramFormat) DiagramExporter {
+	return r.exporters[format.MediaType]
+}
+
+// Without returns a registry of all formats but those with the given names.
+func (r *ExporterRegistry) Without(names ...string) *ExporterRegistry {
+	without := NewExporterRegistry()
+	for _, format := range r.formats {
+		if !slices.Contains(names, format.Name) {
+			without.Register(format, r.exporters[format.MediaType])
+		}
+	}
+	return without
+}
+
+// FormatOf returns the format with the given name.
+func (r *ExporterRegistry) FormatOf(name string) (DiagramFormat, bool) {
+	for _, format := range r.formats {
+		if format.Name == name {
+			return format, true
+		}
+	}
+	return DiagramFormat{}, false
+}
+
+// Negotiate selects the format given by query parameter `format`, or else
+// the format accepted best by the `Accept` header. Without both it selects
+// the default format.
+func (r *ExporterRegistry) Negotiate(req *http.Request) (DiagramFormat, bool) {
+	if name := req.URL.Query().Get("format"); name != "" {
+		return r.FormatOf(name)
+	}
+
+	if len(r.formats) == 0 {
+		return DiagramFormat{}, false
+	}
+
+	accept := req.Header.Get("Accept")
+	if accept == "" {
+		return r.formats[0], true
+	}
+
+	for _, mediaRange := range parseAccept(accept) {
+		for _, format := range r.formats {
+			if matchesMediaRange(format.MediaType, mediaRange) {
+				return format, true
+			}
+		}
+	}
+	return DiagramFormat{}, false
+}
+
+// parseAccept returns the media ranges of the `Accept` header ordered by
+// quality, dropping those not acceptable at all.
+func parseAccept(accept string) []string {
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	var mediaTypes []string
+	for _, r := range ranges {
+		mediaTypes = append(mediaTypes, r.mediaType)
+	}
+	return mediaTypes
+}
+
+// matchesMediaRange checks if the media type is in the range, like
+// `image/svg+xml` in `image/*`.
+func matchesMediaRange(mediaType string, mediaRange string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// invalidFileNameChars are all characters replaced in file names.
+var invalidFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// diagramFileName is the name of the file of the diagram, taken from the
+// path of the request below `/c4/` like `shop-container.svg`.
+func diagramFileName(r *http.Request, format DiagramFormat) string {
+	path := r.URL.Path
+	if i := strings.Index(path, "/c4/"); i >= 0 {
+		path = path[i+len("/c4/"):]
+	}
+
+	name := strings.Trim(invalidFileNameChars.ReplaceAllString(path, "-"), "-")
+	if name == "" {
+		name = "diagram"
+	}
+	return name + "." + format.Extension
+}
+
+// modelExporter exports diagrams with an export of the model.
+func modelExporter(export func(c4Model *C4DiagramModel, w io.Writer) error) DiagramExporter {
+	return DiagramExporterFunc(func(e DiagramExport, w io.Writer) error {
+		return export(e.Model, w)
+	})
+}
+
+// plantUMLServerExporter renders diagrams from PlantUML with the PlantUML
+// server in the given output format. Responses other than success are
+// returned as error.
+func plantUMLServerExporter(plantUMLServer string, outputFormat string) DiagramExporter {
+	return DiagramExporterFunc(func(export DiagramExport, w io.Writer) error {
+		sw := bytes.NewBufferString("")
+		err := export.PlantUML(export.Model, sw)
+		if err != nil {
+			return err
+		}
+
+		response, err := http.Post(
+			fmt.Sprintf("%v/%v", plantUMLServer, outputFormat),
+			"text/plain; charset=UTF-8",
+			sw,
+		)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+
+		if response.StatusCode < 200 || response.StatusCode > 299 {
+			return fmt.Errorf("plantuml rendering of %v failed: %v", outputFormat, response.Status)
+		}
+
+		_, err = io.Copy(w, response.Body)
+		return err
+	})
+}
+
+// svgExporterOf renders svg images natively with renderer `native` and
+// else with the PlantUML server.
+func svgExporterOf(plantUMLServer string) DiagramExporter {
+	native := modelExporter(newSVGExporter().ExportToSVG)
+	plantUML := plantUMLServerExporter(plantUMLServer, "svg")
+
+	return DiagramExporterFunc(func(export DiagramExport, w io.Writer) error {
+		if export.Renderer == "native" {
+			return native.Export(export, w)
+		}
+		return plantUML.Export(export, w)
+	})
+}
@@ -0,0 +1,151 @@
+package c4
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.io/remast/c4stage/shared"
+)
+
+func testExporterRegistry() *ExporterRegistry {
+	r := NewExporterRegistry()
+	r.Register(DiagramFormat{Name: "png", MediaType: "image/png", Extension: "png"}, modelExporter(func(c4Model *C4DiagramModel, w io.Writer) error {
+		_, err := w.Write([]byte("png"))
+		return err
+	}))
+	r.Register(DiagramFormat{Name: "svg", MediaType: "image/svg+xml", Extension: "svg"}, modelExporter(newSVGExporter().ExportToSVG))
+	r.Register(DiagramFormat{Name: "puml", MediaType: "text/x-plantuml", Extension: "puml"}, DiagramExporterFunc(func(export DiagramExport, w io.Writer) error {
+		return export.PlantUML(export.Model, w)
+	}))
+	return r
+}
+
+func TestExporterRegistryNegotiate(t *testing.T) {
+	is := is.New(t)
+	registry := testExporterRegistry()
+
+	negotiate := func(url string, accept string) string {
+		r := httptest.NewRequest("GET", url, nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		format, ok := registry.Negotiate(r)
+		if !ok {
+			return ""
+		}
+		return format.Name
+	}
+
+	is.Equal(negotiate("/c4/context", ""), "png")
+	is.Equal(negotiate("/c4/context?format=puml", "image/svg+xml"), "puml")
+	is.Equal(negotiate("/c4/context?format=gif", ""), "")
+	is.Equal(negotiate("/c4/context", "image/svg+xml"), "svg")
+	is.Equal(negotiate("/c4/context", "text/html, image/*;q=0.9"), "png")
+	is.Equal(negotiate("/c4/context", "image/png;q=0.5, text/x-plantuml"), "puml")
+	is.Equal(negotiate("/c4/context", "text/html,application/xhtml+xml,*/*;q=0.8"), "png")
+	is.Equal(negotiate("/c4/context", "image/png;q=0, application/json"), "")
+}
+
+func TestExporterRegistryRegisterReplaces(t *testing.T) {
+	is := is.New(t)
+	registry := testExporterRegistry()
+
+	registry.Register(DiagramFormat{Name: "svg", MediaType: "image/svg+xml", Extension: "svg"}, modelExporter(newD2Exporter().ExportToD2))
+
+	is.Equal(len(registry.Formats()), 3)
+	is.Equal(registry.Formats()[1].Name, "svg")
+}
+
+func TestExporterRegistryWithout(t *testing.T) {
+	is := is.New(t)
+	registry := testExporterRegistry().Without("png")
+
+	is.Equal(len(registry.Formats()), 2)
+	is.Equal(registry.Formats()[0].Name, "svg")
+	_, ok := registry.FormatOf("png")
+	is.True(!ok)
+}
+
+func TestDiagramFormatContentType(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(DiagramFormat{MediaType: "image/png"}.ContentType(), "image/png")
+	is.Equal(DiagramFormat{MediaType: "image/svg+xml"}.ContentType(), "image/svg+xml; charset=utf-8")
+	is.Equal(DiagramFormat{MediaType: "text/vnd.d2"}.ContentType(), "text/vnd.d2; charset=utf-8")
+}
+
+func TestDiagramFileName(t *testing.T) {
+	is := is.New(t)
+	svg := DiagramFormat{Extension: "svg"}
+
+	is.Equal(diagramFileName(httptest.NewRequest("GET", "/api/c4/shop/container?format=svg", nil), svg), "shop-container.svg")
+	is.Equal(diagramFileName(httptest.NewRequest("GET", "/api/c4/-/neighbourhood/component:shop-api", nil), svg), "neighbourhood-component-shop-api.svg")
+	is.Equal(diagramFileName(httptest.NewRequest("GET", "/", nil), svg), "diagram.svg")
+}
+
+func TestRenderModelSetsHeaders(t *testing.T) {
+	is := is.New(t)
+	c := &C4Controller{Config: &shared.Config{}, Exporters: testExporterRegistry()}
+
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "shop", Title: "Shop"})
+	plantUML := newPlantUMLExporter().ExportToPlantUMLContext
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/c4/shop/context", nil)
+	r.Header.Set("Accept", "text/x-plantuml")
+	c.renderModel(w, r, m, plantUML)
+
+	is.Equal(w.Code, http.StatusOK)
+	is.Equal(w.Header().Get("Content-Type"), "text/x-plantuml; charset=utf-8")
+	is.Equal(w.Header().Get("Content-Disposition"), `inline; filename="shop-context.puml"`)
+	is.Equal(w.Header().Get("Vary"), "Accept")
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/api/c4/shop/context", nil)
+	r.Header.Set("Accept", "application/json")
+	c.renderModel(w, r, m, plantUML)
+
+	is.Equal(w.Code, http.StatusNotAcceptable)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/api/c4/shop/context?format=gif", nil)
+	c.renderModel(w, r, m, plantUML)
+
+	is.Equal(w.Code, http.StatusBadRequest)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/api/c4/shop/context?renderer=native", nil)
+	c.renderModel(w, r, m, plantUML)
+
+	is.Equal(w.Code, http.StatusOK)
+	is.Equal(w.Header().Get("Content-Type"), "image/svg+xml; charset=utf-8")
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/api/c4/shop/context?renderer=native&format=png", nil)
+	c.renderModel(w, r, m, plantUML)
+
+	is.Equal(w.Code, http.StatusBadRequest)
+}
+
+func TestPlantUMLServerExporterFails(t *testing.T) {
+	is := is.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "syntax error", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	export := DiagramExport{
+		Model:    &C4DiagramModel{},
+		PlantUML: newPlantUMLExporter().ExportToPlantUMLContext,
+	}
+	w := bytes.NewBufferString("")
+	err := plantUMLServerExporter(server.URL, "png").Export(export, w)
+
+	is.True(err != nil)
+	is.Equal(w.Len(), 0)
+}
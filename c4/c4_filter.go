@@ -0,0 +1,152 @@
+package c4
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// DiagramFilter restricts the elements of a diagram by tag, lifecycle, owner,
+// domain, type and a name pattern of the system, like `shop-*`. The zero
+// value matches all elements.
+type DiagramFilter struct {
+	Tags              []string
+	ExcludeTags       []string
+	Lifecycles        []string
+	ExcludeLifecycles []string
+	Owners            []string
+	ExcludeOwners     []string
+	Domains           []string
+	ExcludeDomains    []string
+	Types             []string
+	ExcludeTypes      []string
+	System            string
+}
+
+// filterProperties maps the list fields of the filter to the properties of
+// the nodes they apply to.
+var filterProperties = []struct {
+	property string
+	param    string
+	include  func(f DiagramFilter) []string
+	exclude  func(f DiagramFilter) []string
+}{
+	{"lifecycle", "Lifecycles", func(f DiagramFilter) []string { return f.Lifecycles }, func(f DiagramFilter) []string { return f.ExcludeLifecycles }},
+	{"owner", "Owners", func(f DiagramFilter) []string { return f.Owners }, func(f DiagramFilter) []string { return f.ExcludeOwners }},
+	{"domain", "Domains", func(f DiagramFilter) []string { return f.Domains }, func(f DiagramFilter) []string { return f.ExcludeDomains }},
+	{"type", "Types", func(f DiagramFilter) []string { return f.Types }, func(f DiagramFilter) []string { return f.ExcludeTypes }},
+}
+
+// DiagramFilterOf reads the filter from the query parameters `tag`,
+// `lifecycle`, `owner`, `domain` and `type`, their exclusions like
+// `excludeTag` and `system`. Values may be repeated or comma separated.
+func DiagramFilterOf(r *http.Request) DiagramFilter {
+	query := r.URL.Query()
+	values := func(key string) []string {
+		var values []string
+		for _, value := range query[key] {
+			for _, v := range strings.Split(value, ",") {
+				v = strings.TrimSpace(v)
+				if v != "" {
+					values = append(values, v)
+				}
+			}
+		}
+		return values
+	}
+
+	return DiagramFilter{
+		Tags:              values("tag"),
+		ExcludeTags:       values("excludeTag"),
+		Lifecycles:        values("lifecycle"),
+		ExcludeLifecycles: values("excludeLifecycle"),
+		Owners:            values("owner"),
+		ExcludeOwners:     values("excludeOwner"),
+		Domains:           values("domain"),
+		ExcludeDomains:    values("excludeDomain"),
+		Types:             values("type"),
+		ExcludeTypes:      values("excludeType"),
+		System:            strings.TrimSpace(query.Get("system")),
+	}
+}
+
+// Where returns the Cypher condition matching the node with the given
+// variable and label, using the parameters of Params.
+func (f DiagramFilter) Where(variable string, label string) string {
+	var conditions []string
+
+	if len(f.Tags) > 0 {
+		conditions = append(conditions, fmt.Sprintf("any(t IN coalesce(%v.tags, []) WHERE t IN $filterTags)", variable))
+	}
+	if len(f.ExcludeTags) > 0 {
+		conditions = append(conditions, fmt.Sprintf("none(t IN coalesce(%v.tags, []) WHERE t IN $filterExcludeTags)", variable))
+	}
+
+	for _, p := range filterProperties {
+		if len(p.include(f)) > 0 {
+			conditions = append(conditions, fmt.Sprintf("%v.%v IN $filter%v", variable, p.property, p.param))
+		}
+		if len(p.exclude(f)) > 0 {
+			conditions = append(conditions, fmt.Sprintf("NOT coalesce(%v.%v, '') IN $filterExclude%v", variable, p.property, p.param))
+		}
+	}
+
+	if f.System != "" {
+		systemProperty := "system"
+		if label == "System" {
+			systemProperty = "name"
+		}
+		conditions = append(conditions, fmt.Sprintf("coalesce(%v.%v, '') =~ $filterSystem", variable, systemProperty))
+	}
+
+	if len(conditions) == 0 {
+		return "true"
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// WhereNotExcluded returns the Cypher condition matching the node with the
+// given variable unless excluded by the exclusions of the filter. It applies
+// to neighbours of the filtered elements, like persons and external systems,
+// which are kept unless explicitly excluded.
+func (f DiagramFilter) WhereNotExcluded(variable string) string {
+	var conditions []string
+
+	if len(f.ExcludeTags) > 0 {
+		conditions = append(conditions, fmt.Sprintf("none(t IN coalesce(%v.tags, []) WHERE t IN $filterExcludeTags)", variable))
+	}
+
+	for _, p := range filterProperties {
+		if len(p.exclude(f)) > 0 {
+			conditions = append(conditions, fmt.Sprintf("NOT coalesce(%v.%v, '') IN $filterExclude%v", variable, p.property, p.param))
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "true"
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// Params returns the query parameters of the conditions of Where, merged
+// into the given parameters.
+func (f DiagramFilter) Params(params map[string]any) map[string]any {
+	params["filterTags"] = f.Tags
+	params["filterExcludeTags"] = f.ExcludeTags
+	for _, p := range filterProperties {
+		params["filter"+p.param] = p.include(f)
+		params["filterExclude"+p.param] = p.exclude(f)
+	}
+	params["filterSystem"] = globPattern(f.System)
+	return params
+}
+
+// globPattern converts a name pattern with wildcards `*` and `?` into a case
+// insensitive regular expression.
+func globPattern(glob string) string {
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+	pattern = strings.ReplaceAll(pattern, `\?`, ".")
+	return "(?i)" + pattern
+}
@@ -0,0 +1,84 @@
+package c4
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDiagramFilterOf(t *testing.T) {
+	is := is.New(t)
+
+	r := httptest.NewRequest("GET", "/c4/context?tag=pci,%20gdpr&tag=core&excludeLifecycle=deprecated&owner=team-a&system=shop-*", nil)
+
+	filter := DiagramFilterOf(r)
+
+	is.Equal(filter.Tags, []string{"pci", "gdpr", "core"})
+	is.Equal(filter.ExcludeLifecycles, []string{"deprecated"})
+	is.Equal(filter.Owners, []string{"team-a"})
+	is.Equal(filter.System, "shop-*")
+	is.Equal(len(filter.Domains), 0)
+}
+
+func TestDiagramFilterWhereWithoutFilter(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(DiagramFilter{}.Where("s", "System"), "true")
+}
+
+func TestDiagramFilterWhere(t *testing.T) {
+	is := is.New(t)
+
+	filter := DiagramFilter{
+		Tags:         []string{"pci"},
+		ExcludeTypes: []string{"library"},
+		Domains:      []string{"commerce"},
+		System:       "shop-*",
+	}
+
+	is.Equal(
+		filter.Where("c", "Component"),
+		"any(t IN coalesce(c.tags, []) WHERE t IN $filterTags) AND c.domain IN $filterDomains AND NOT coalesce(c.type, '') IN $filterExcludeTypes AND coalesce(c.system, '') =~ $filterSystem",
+	)
+	is.Equal(
+		DiagramFilter{System: "shop"}.Where("s", "System"),
+		"coalesce(s.name, '') =~ $filterSystem",
+	)
+}
+
+func TestDiagramFilterWhereNotExcluded(t *testing.T) {
+	is := is.New(t)
+
+	filter := DiagramFilter{
+		Tags:         []string{"pci"},
+		ExcludeTypes: []string{"library"},
+		System:       "shop-*",
+	}
+
+	is.Equal(filter.WhereNotExcluded("system"), "NOT coalesce(system.type, '') IN $filterExcludeTypes")
+	is.Equal(DiagramFilter{Owners: []string{"team-a"}}.WhereNotExcluded("system"), "true")
+}
+
+func TestDiagramFilterParams(t *testing.T) {
+	is := is.New(t)
+
+	filter := DiagramFilter{Owners: []string{"team-a"}, System: "shop-*"}
+
+	params := filter.Params(map[string]any{"snapshot": "live"})
+
+	is.Equal(params["snapshot"], "live")
+	is.Equal(params["filterOwners"], []string{"team-a"})
+	is.Equal(params["filterSystem"], "(?i)shop-.*")
+}
+
+func TestGlobPattern(t *testing.T) {
+	is := is.New(t)
+
+	pattern := regexp.MustCompile("^" + globPattern("shop?-*") + "$")
+
+	is.True(pattern.MatchString("Shop1-api"))
+	is.True(!pattern.MatchString("shopx1-api"))
+	is.True(!pattern.MatchString("shopping-api"))
+}
@@ -0,0 +1,163 @@
+package c4
+
+import (
+	"fmt"
+	"slices"
+
+	"github.io/remast/c4stage/catalog"
+)
+
+// Flow is a named request flow of a system as ordered steps between
+// systems and containers, rendered as dynamic diagram.
+type Flow struct {
+	System      string     `json:"system"`
+	Name        string     `json:"name"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Steps       []FlowStep `json:"steps"`
+}
+
+// FlowStep is a step of a flow from the entity with ref Source to the
+// entity with ref Target.
+type FlowStep struct {
+	Source      string `json:"source"`
+	Target      string `json:"target"`
+	Description string `json:"description"`
+	Technology  string `json:"technology"`
+}
+
+// Participant is an element taking part in a sequence diagram of a flow.
+type Participant struct {
+	ID    string
+	Title string
+	Kind  string
+}
+
+// SequenceStep is a numbered step of a sequence diagram of a flow.
+type SequenceStep struct {
+	Number int
+	Relation
+}
+
+// Validate checks that the flow has steps and all steps refer to systems
+// or containers defined in the landscape, not to placeholders of entities
+// only referenced by others.
+func (f *Flow) Validate(landscape *catalog.Landscape) []string {
+	var problems []string
+
+	if f.Name == "" {
+		problems = append(problems, "flow has no name")
+	}
+	if len(f.Steps) == 0 {
+		problems = append(problems, "flow has no steps")
+	}
+
+	var refs []string
+	for _, system := range landscape.Systems {
+		if system.Defined {
+			refs = append(refs, system.Ref())
+		}
+	}
+	for _, container := range landscape.Containers {
+		if container.Defined {
+			refs = append(refs, container.Ref())
+		}
+	}
+
+	for i, step := range f.Steps {
+		for _, ref := range []string{step.Source, step.Target} {
+			if !slices.Contains(refs, normalizeRef(ref)) {
+				problems = append(problems, fmt.Sprintf("step %v refers to unknown entity %v", i+1, ref))
+			}
+		}
+	}
+
+	return problems
+}
+
+// Refs returns the refs of all entities taking part in the flow in order
+// of their first appearance.
+func (f *Flow) Refs() []string {
+	var refs []string
+	for _, step := range f.Steps {
+		for _, ref := range []string{normalizeRef(step.Source), normalizeRef(step.Target)} {
+			if !slices.Contains(refs, ref) {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// DynamicDiagram builds a model of all entities of the flow with the steps
+// of the flow as relations in order.
+func (f *Flow) DynamicDiagram(landscape *catalog.Landscape) *C4DiagramModel {
+	c4Model := LandscapeDiagram(landscape, f.Refs())
+
+	c4Model.Relations = nil
+	for _, step := range f.Steps {
+		c4Model.Relations = append(c4Model.Relations, Relation{
+			SourceID:   c4Model.idOfRef(normalizeRef(step.Source)),
+			TargetID:   c4Model.idOfRef(normalizeRef(step.Target)),
+			Label:      step.Description,
+			Technology: step.Technology,
+		})
+	}
+
+	return c4Model
+}
+
+// Participants returns the elements of the model in order of their first
+// appearance in the relations.
+func (m C4DiagramModel) Participants() []Participant {
+	var participants []Participant
+	for _, relation := range m.Relations {
+		for _, id := range []string{relation.SourceID, relation.TargetID} {
+			if slices.ContainsFunc(participants, func(p Participant) bool { return p.ID == id }) {
+				continue
+			}
+			participants = append(participants, m.participantOf(id))
+		}
+	}
+	return participants
+}
+
+// SequenceSteps returns the relations of the model numbered in order.
+func (m C4DiagramModel) SequenceSteps() []SequenceStep {
+	var steps []SequenceStep
+	for i, relation := range m.Relations {
+		steps = append(steps, SequenceStep{
+			Number:   i + 1,
+			Relation: relation,
+		})
+	}
+	return steps
+}
+
+func (m C4DiagramModel) participantOf(id string) Participant {
+	for _, system := range m.allSystems() {
+		if system.ID == id {
+			kind := "participant"
+			if system.IsPerson() {
+				kind = "actor"
+			}
+			return Participant{ID: id, Title: system.Title, Kind: kind}
+		}
+	}
+	for _, container := range m.Containers {
+		if container.ID == id {
+			kind := "participant"
+			if container.IsDatabase() {
+				kind = "database"
+			}
+			return Participant{ID: id, Title: container.Title, Kind: kind}
+		}
+	}
+	return Participant{ID: id, Title: id, Kind: "participant"}
+}
+
+// normalizeRef completes refs without kind like `my-component`.
+func normalizeRef(ref string) string {
+	label, name := catalog.ParseRef(ref)
+	return catalog.EntityRef(label, name)
+}
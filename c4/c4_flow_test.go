@@ -0,0 +1,102 @@
+package c4
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func checkoutFlow() *Flow {
+	return &Flow{
+		System: "shop",
+		Name:   "checkout",
+		Steps: []FlowStep{
+			{Source: "system:customer", Target: "shop-ui", Description: "places order", Technology: "HTTPS"},
+			{Source: "component:shop-ui", Target: "component:shop-db", Description: "stores order", Technology: "SQL"},
+			{Source: "shop-ui", Target: "system:customer", Description: "confirms order"},
+		},
+	}
+}
+
+func TestValidateFlow(t *testing.T) {
+	is := is.New(t)
+
+	problems := checkoutFlow().Validate(testLandscape())
+
+	is.Equal(len(problems), 0)
+}
+
+func TestValidateFlowWithUnknownEntity(t *testing.T) {
+	is := is.New(t)
+
+	flow := checkoutFlow()
+	flow.Steps[1].Target = "component:missing"
+
+	problems := flow.Validate(testLandscape())
+
+	is.Equal(problems, []string{"step 2 refers to unknown entity component:missing"})
+}
+
+func TestValidateFlowWithPlaceholder(t *testing.T) {
+	is := is.New(t)
+
+	flow := checkoutFlow()
+	flow.Steps[1].Target = "system:psp"
+
+	problems := flow.Validate(testLandscape())
+
+	is.Equal(problems, []string{"step 2 refers to unknown entity system:psp"})
+}
+
+func TestValidateFlowWithoutSteps(t *testing.T) {
+	is := is.New(t)
+
+	flow := &Flow{System: "shop", Name: "checkout"}
+
+	problems := flow.Validate(testLandscape())
+
+	is.Equal(problems, []string{"flow has no steps"})
+}
+
+func TestFlowDynamicDiagramKeepsStepsInOrder(t *testing.T) {
+	is := is.New(t)
+
+	m := checkoutFlow().DynamicDiagram(testLandscape())
+
+	is.Equal(len(m.Persons), 1)
+	is.Equal(len(m.Relations), 3)
+	is.Equal(m.Relations[0].SourceID, "s1")
+	is.Equal(m.Relations[0].Technology, "HTTPS")
+	is.Equal(m.Relations[2].SourceID, "c1")
+	is.Equal(m.Relations[2].TargetID, "s1")
+}
+
+func TestExportToPlantUMLDynamic(t *testing.T) {
+	is := is.New(t)
+	e := newPlantUMLExporter()
+	sw := bytes.NewBufferString("")
+
+	err := e.ExportToPlantUMLDynamic(checkoutFlow().DynamicDiagram(testLandscape()), sw)
+	puml := sw.String()
+
+	is.NoErr(err)
+	is.True(strings.Contains(puml, "<C4/C4_Dynamic>"))
+	is.True(strings.Contains(puml, `Rel(c1, c2, "stores order", "SQL")`))
+}
+
+func TestExportToPlantUMLSequence(t *testing.T) {
+	is := is.New(t)
+	e := newPlantUMLExporter()
+	sw := bytes.NewBufferString("")
+
+	err := e.ExportToPlantUMLSequence(checkoutFlow().DynamicDiagram(testLandscape()), sw)
+	puml := sw.String()
+
+	is.NoErr(err)
+	is.True(strings.Contains(puml, `actor "Customer" as s1`))
+	is.True(strings.Contains(puml, `database "Shop DB" as c2`))
+	is.True(strings.Contains(puml, "s1 -> c1 : places order [HTTPS]"))
+	is.True(strings.Contains(puml, "c1 -> s1 : confirms order\n"))
+}
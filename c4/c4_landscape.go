@@ -0,0 +1,234 @@
+package c4
+
+import (
+	"slices"
+
+	"github.io/remast/c4stage/analysis"
+	"github.io/remast/c4stage/catalog"
+)
+
+// LandscapeDiagram builds a model of the entities of the landscape with the
+// given refs and all dependencies between them. Containers are placed in the
+// boundaries of their systems.
+func LandscapeDiagram(landscape *catalog.Landscape, refs []string) *C4DiagramModel {
+	c4Model := &C4DiagramModel{}
+
+	ids := make(map[string]string)
+	var boundaries []string
+
+	for _, container := range landscape.Containers {
+		ids[container.Ref()] = AsID(container.ID)
+		if !slices.Contains(refs, container.Ref()) {
+			continue
+		}
+
+		c4Model.AddContainer(containerOfEntity(container))
+		boundaries = append(boundaries, container.System)
+	}
+
+	for _, system := range landscape.Systems {
+		ids[system.Ref()] = AsID(system.ID)
+		if !slices.Contains(refs, system.Ref()) && !slices.Contains(boundaries, system.Name) {
+			continue
+		}
+
+		c4Model.AddSystem(systemOfEntity(system))
+	}
+
+	for _, relation := range landscape.Relations {
+		if relation.Type != "DEPENDS_ON" {
+			continue
+		}
+		if !slices.Contains(refs, relation.Source) || !slices.Contains(refs, relation.Target) {
+			continue
+		}
+
+		c4Model.AddRelation(Relation{
+			SourceID: ids[relation.Source],
+			TargetID: ids[relation.Target],
+			Label:    AsRelation(relation.Type),
+		})
+	}
+
+	c4Model.PostProcess()
+
+	return c4Model
+}
+
+// PathDiagram builds a model of all entities on the given paths, tagging the
+// relations on the paths and the ends of the paths with `path`. As APIs are
+// no elements of C4 diagrams they are drawn as the systems providing them.
+func PathDiagram(landscape *catalog.Landscape, from string, to string, paths [][]catalog.Relation) *C4DiagramModel {
+	resolve := providerResolver(landscape)
+
+	var relations []catalog.Relation
+	for _, path := range paths {
+		relations = append(relations, path...)
+	}
+
+	c4Model := relationsDiagram(landscape, nil, relations)
+
+	for _, relation := range relations {
+		source, target := resolve(relation.Source), resolve(relation.Target)
+		if source == "" || target == "" || source == target {
+			continue
+		}
+		c4Model.TagRelation(source, target, "path")
+	}
+
+	c4Model.TagElement(resolve(from), "path")
+	c4Model.TagElement(resolve(to), "path")
+
+	return c4Model
+}
+
+// ImpactDiagram builds a model of all entities reached by the impact and
+// the relations between them, tagging the elements by their distance in
+// hops. APIs are drawn as the systems providing them, with the distance of
+// the nearest entity drawn as the system.
+func ImpactDiagram(landscape *catalog.Landscape, impact *analysis.Impact) *C4DiagramModel {
+	resolve := providerResolver(landscape)
+
+	var refs []string
+	for _, ref := range impact.Refs() {
+		if resolve(ref) != "" && !slices.Contains(refs, resolve(ref)) {
+			refs = append(refs, resolve(ref))
+		}
+	}
+
+	c4Model := relationsDiagram(landscape, refs, relationsBetween(landscape, impact.Refs()))
+
+	tagged := []string{resolve(impact.Ref)}
+	c4Model.TagElement(resolve(impact.Ref), AsHopTag(0))
+	for _, hop := range impact.Hops {
+		ref := resolve(hop.Node.Ref)
+		if slices.Contains(tagged, ref) {
+			continue
+		}
+		tagged = append(tagged, ref)
+		c4Model.TagElement(ref, AsHopTag(hop.Distance))
+	}
+
+	return c4Model
+}
+
+// NeighbourhoodDiagram builds a model of the entity with the given ref and
+// all entities reached by the given relations, tagging the entity with
+// `focus`. APIs are drawn as the systems providing them.
+func NeighbourhoodDiagram(landscape *catalog.Landscape, ref string, relations []catalog.Relation) *C4DiagramModel {
+	resolve := providerResolver(landscape)
+
+	var refs []string
+	if resolve(ref) != "" {
+		refs = append(refs, resolve(ref))
+	}
+
+	c4Model := relationsDiagram(landscape, refs, relations)
+	c4Model.TagElement(resolve(ref), "focus")
+
+	return c4Model
+}
+
+// relationsDiagram builds a model of the entities with the given refs and
+// all entities of the given relations together with the relations. APIs
+// are resolved to the systems providing them.
+func relationsDiagram(landscape *catalog.Landscape, refs []string, relations []catalog.Relation) *C4DiagramModel {
+	resolve := providerResolver(landscape)
+
+	for _, relation := range relations {
+		for _, ref := range []string{resolve(relation.Source), resolve(relation.Target)} {
+			if ref != "" && !slices.Contains(refs, ref) {
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	c4Model := LandscapeDiagram(landscape, refs)
+
+	for _, relation := range relations {
+		source, target := resolve(relation.Source), resolve(relation.Target)
+		if source == "" || target == "" || source == target {
+			continue
+		}
+
+		sourceID, targetID := c4Model.idOfRef(source), c4Model.idOfRef(target)
+		if !c4Model.hasRelation(sourceID, targetID) {
+			label := AsRelation(relation.Type)
+			if relation.Type == "CONSUMES" {
+				_, apiName := catalog.ParseRef(relation.Target)
+				label = AsUsage(apiName, "")
+			}
+
+			c4Model.AddRelation(Relation{
+				SourceID: sourceID,
+				TargetID: targetID,
+				Label:    label,
+			})
+		}
+	}
+
+	return c4Model
+}
+
+// relationsBetween returns all dependencies, consumed and provided APIs of
+// the landscape between entities with the given refs.
+func relationsBetween(landscape *catalog.Landscape, refs []string) []catalog.Relation {
+	var relations []catalog.Relation
+	for _, relation := range landscape.Relations {
+		if !slices.Contains([]string{"DEPENDS_ON", "CONSUMES", "PROVIDES"}, relation.Type) {
+			continue
+		}
+		if !slices.Contains(refs, relation.Source) || !slices.Contains(refs, relation.Target) {
+			continue
+		}
+		relations = append(relations, relation)
+	}
+	return relations
+}
+
+// providerResolver returns a function resolving refs of APIs to the refs of
+// the systems providing them, or the empty string for APIs without system.
+// All other refs are returned as is.
+func providerResolver(landscape *catalog.Landscape) func(ref string) string {
+	providers := make(map[string]string)
+	for _, api := range landscape.APIs {
+		providers[api.Ref()] = ""
+		if api.System != "" {
+			providers[api.Ref()] = catalog.EntityRef("System", api.System)
+		}
+	}
+	return func(ref string) string {
+		if provider, ok := providers[ref]; ok {
+			return provider
+		}
+		return ref
+	}
+}
+
+func systemOfEntity(entity catalog.System) *System {
+	system := &System{
+		ID:          AsID(entity.ID),
+		Label:       entity.Name,
+		Title:       entity.Title,
+		Description: entity.Description,
+		Type:        entity.Type,
+	}
+	system.AddTag(entity.Lifecycle)
+
+	return system
+}
+
+func containerOfEntity(entity catalog.Container) *Container {
+	container := &Container{
+		ID:          AsID(entity.ID),
+		Label:       entity.Name,
+		Title:       entity.Title,
+		Description: entity.Description,
+		Type:        entity.Type,
+		System:      entity.System,
+		Tags:        slices.Clone(entity.Tags),
+	}
+	container.AddTag(entity.Lifecycle)
+
+	return container
+}
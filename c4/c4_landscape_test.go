@@ -0,0 +1,195 @@
+package c4
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.io/remast/c4stage/analysis"
+	"github.io/remast/c4stage/catalog"
+)
+
+// testLandscape is the landscape of a web shop used by its customers and
+// paying with the external payment service provider psp, which is only
+// referenced by its API and not defined itself.
+func testLandscape() *catalog.Landscape {
+	return &catalog.Landscape{
+		Systems: []catalog.System{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:1", Name: "customer", Title: "Customer", Type: "person", Defined: true}},
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:2", Name: "shop", Title: "Shop", Tags: []string{"core"}, Defined: true}},
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:3", Name: "psp", Title: "PSP", Type: "external"}},
+		},
+		Containers: []catalog.Container{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "c:3", Name: "shop-api", Title: "Shop API", Type: "service", Lifecycle: "production", Defined: true}, System: "shop",
+				Usages: map[string]catalog.Usage{"payments-api": {Description: "pays orders"}}},
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "c:2", Name: "shop-db", Title: "Shop DB", Type: "database", Defined: true}, System: "shop"},
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "c:1", Name: "shop-ui", Title: "Shop UI", Defined: true}, System: "shop"},
+		},
+		APIs: []catalog.API{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "a:1", Name: "payments-api", Type: "openapi"}, System: "psp"},
+		},
+		Relations: []catalog.Relation{
+			{Source: "system:customer", Target: "system:shop", Type: "DEPENDS_ON"},
+			{Source: "system:shop", Target: "component:shop-db", Type: "DEPENDS_ON"},
+			{Source: "component:shop-api", Target: "component:shop-db", Type: "DEPENDS_ON"},
+			{Source: "component:shop-api", Target: "api:payments-api", Type: "CONSUMES"},
+		},
+	}
+}
+
+func TestLandscapeDiagramWithContainers(t *testing.T) {
+	is := is.New(t)
+
+	l := &catalog.Landscape{
+		Systems: []catalog.System{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:1", Name: "shop"}},
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:2", Name: "other"}},
+		},
+		Containers: []catalog.Container{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "c:1", Name: "shop-ui"}, System: "shop"},
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "c:2", Name: "shop-api"}, System: "shop"},
+		},
+		Relations: []catalog.Relation{
+			{Source: "component:shop-ui", Target: "component:shop-api", Type: "DEPENDS_ON"},
+			{Source: "system:shop", Target: "system:other", Type: "DEPENDS_ON"},
+		},
+	}
+
+	m := LandscapeDiagram(l, []string{"component:shop-ui", "component:shop-api"})
+	m.TagRelation("component:shop-ui", "component:shop-api", "cycle")
+
+	is.Equal(len(m.Systems), 1)
+	is.Equal(len(m.Systems[0].Containers), 2)
+	is.Equal(len(m.Relations), 1)
+	is.Equal(m.Relations[0].SourceID, "c1")
+	is.Equal(m.Relations[0].AsTags(), "cycle")
+}
+
+func TestPathDiagramViaAPI(t *testing.T) {
+	is := is.New(t)
+
+	l := &catalog.Landscape{
+		Systems: []catalog.System{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:1", Name: "shop"}},
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:2", Name: "psp", Type: "external"}},
+		},
+		Containers: []catalog.Container{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "c:1", Name: "shop-ui"}, System: "shop"},
+		},
+		APIs: []catalog.API{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "a:1", Name: "payments"}, System: "psp"},
+		},
+	}
+	paths := [][]catalog.Relation{{
+		{Source: "component:shop-ui", Target: "api:payments", Type: "CONSUMES"},
+		{Source: "api:payments", Target: "system:psp", Type: "PROVIDED_BY"},
+	}}
+
+	m := PathDiagram(l, "component:shop-ui", "system:psp", paths)
+
+	is.Equal(len(m.Systems), 1)
+	is.Equal(len(m.ExternalSystems), 1)
+	is.Equal(len(m.Relations), 1)
+	is.Equal(m.Relations[0].SourceID, "c1")
+	is.Equal(m.Relations[0].TargetID, "s2")
+	is.Equal(m.Relations[0].Label, "uses payments")
+	is.Equal(m.Relations[0].AsTags(), "path")
+	is.Equal(m.ExternalSystems[0].AsTags(), "path")
+}
+
+func TestImpactDiagramOfAPI(t *testing.T) {
+	is := is.New(t)
+
+	l := &catalog.Landscape{
+		Systems: []catalog.System{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:1", Name: "shop"}},
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:2", Name: "psp", Type: "external"}},
+		},
+		Containers: []catalog.Container{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "c:1", Name: "shop-ui"}, System: "shop"},
+		},
+		APIs: []catalog.API{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "a:1", Name: "payments"}, System: "psp"},
+		},
+		Relations: []catalog.Relation{
+			{Source: "component:shop-ui", Target: "api:payments", Type: "CONSUMES"},
+			{Source: "system:psp", Target: "api:payments", Type: "PROVIDES"},
+		},
+	}
+	impact := analysis.Traverse(analysis.EntityGraph(l), "api:payments", analysis.DirectionUpstream, 1)
+
+	m := ImpactDiagram(l, impact)
+
+	is.Equal(len(m.ExternalSystems), 1)
+	is.Equal(m.ExternalSystems[0].AsTags(), "hop0")
+	is.Equal(len(m.Systems), 1)
+	is.Equal(m.Systems[0].Containers[0].AsTags(), "hop1")
+	is.Equal(len(m.Relations), 1)
+	is.Equal(m.Relations[0].SourceID, "c1")
+	is.Equal(m.Relations[0].TargetID, "s2")
+	is.Equal(m.Relations[0].Label, "uses payments")
+}
+
+func TestNeighbourhoodDiagram(t *testing.T) {
+	is := is.New(t)
+
+	l := &catalog.Landscape{
+		Systems: []catalog.System{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:1", Name: "shop"}},
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:2", Name: "orders"}},
+		},
+		Containers: []catalog.Container{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "c:1", Name: "shop-ui"}, System: "shop"},
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "c:2", Name: "shop-api"}, System: "shop"},
+		},
+		APIs: []catalog.API{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "a:1", Name: "orders-api"}, System: "orders"},
+		},
+	}
+	relations := []catalog.Relation{
+		{Source: "component:shop-ui", Target: "component:shop-api", Type: "DEPENDS_ON"},
+		{Source: "component:shop-api", Target: "api:orders-api", Type: "CONSUMES"},
+	}
+
+	m := NeighbourhoodDiagram(l, "component:shop-api", relations)
+
+	is.Equal(len(m.Systems), 2)
+	is.Equal(len(m.Systems[0].Containers), 2)
+	is.Equal(len(m.Relations), 2)
+	is.Equal(m.Relations[1].Label, "uses orders-api")
+	is.Equal(m.Containers[1].AsTags(), "focus")
+}
+
+func TestNeighbourhoodDiagramWithoutRelations(t *testing.T) {
+	is := is.New(t)
+
+	l := &catalog.Landscape{
+		Systems: []catalog.System{
+			{EntityEnvelope: catalog.EntityEnvelope{ID: "s:1", Name: "shop"}},
+		},
+	}
+
+	m := NeighbourhoodDiagram(l, "system:shop", nil)
+
+	is.Equal(len(m.Systems), 1)
+	is.Equal(m.Systems[0].AsTags(), "focus")
+}
+
+func TestRelationsBetween(t *testing.T) {
+	is := is.New(t)
+
+	l := &catalog.Landscape{
+		Relations: []catalog.Relation{
+			{Source: "component:shop-ui", Target: "component:shop-api", Type: "DEPENDS_ON"},
+			{Source: "component:shop-api", Target: "api:orders-api", Type: "CONSUMES"},
+			{Source: "component:shop-ui", Target: "api:orders-api", Type: "CONSUMES"},
+			{Source: "component:shop-api", Target: "component:shop-db", Type: "DEPENDS_ON"},
+			{Source: "component:shop-api", Target: "system:shop", Type: "PART_OF"},
+		},
+	}
+
+	relations := relationsBetween(l, []string{"component:shop-ui", "component:shop-api", "api:orders-api", "system:shop"})
+
+	is.Equal(len(relations), 3)
+	is.Equal(relations[2].Source, "component:shop-ui")
+	is.Equal(relations[2].Target, "api:orders-api")
+}
@@ -0,0 +1,522 @@
+package c4
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+const (
+	layoutNodeWidth        = 220.0
+	layoutNodeHeight       = 130.0
+	layoutGap              = 50.0
+	layoutMargin           = 40.0
+	layoutBoundaryPadding  = 20.0
+	layoutBoundaryTitle    = 30.0
+	layoutOrderingSweeps   = 3
+	layoutLayerGapPerDepth = 2*layoutBoundaryPadding + layoutBoundaryTitle
+)
+
+// Layout is a model laid out in layers, with the nodes and boundaries
+// placed at absolute positions and the edges as straight lines between the
+// borders of their nodes.
+type Layout struct {
+	Width  float64
+	Height float64
+	// Boundaries are ordered outer to inner.
+	Boundaries []*LayoutBoundary
+	Nodes      []*LayoutNode
+	Edges      []*LayoutEdge
+}
+
+type LayoutBox struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// LayoutNode is an element drawn as box, of kind person, system, external,
+// container, database, component or api.
+type LayoutNode struct {
+	LayoutBox
+	ID          string
+	Title       string
+	Kind        string
+	Technology  string
+	Description string
+	Tags        []string
+	// Parent is the ID of the boundary containing the node.
+	Parent string
+	layer  int
+}
+
+// LayoutBoundary is a boundary around elements, of kind system, container
+// or deployment.
+type LayoutBoundary struct {
+	LayoutBox
+	ID     string
+	Title  string
+	Kind   string
+	Tags   []string
+	Parent string
+}
+
+type LayoutEdge struct {
+	SourceID   string
+	TargetID   string
+	Label      string
+	Technology string
+	Tags       []string
+	Start      LayoutPoint
+	End        LayoutPoint
+}
+
+type LayoutPoint struct {
+	X float64
+	Y float64
+}
+
+func (b LayoutBox) CenterX() float64 {
+	return b.X + b.Width/2
+}
+
+func (b LayoutBox) CenterY() float64 {
+	return b.Y + b.Height/2
+}
+
+func (b LayoutBox) Bottom() float64 {
+	return b.Y + b.Height
+}
+
+// Middle is the point in the middle of the edge, where it is labelled.
+func (e LayoutEdge) Middle() LayoutPoint {
+	return LayoutPoint{X: (e.Start.X + e.End.X) / 2, Y: (e.Start.Y + e.End.Y) / 2}
+}
+
+// layoutItem is a node or a boundary with its children in the tree of
+// elements to lay out.
+type layoutItem struct {
+	node     *LayoutNode
+	boundary *LayoutBoundary
+	children []*layoutItem
+}
+
+type layouter struct {
+	layout   *Layout
+	root     *layoutItem
+	nodes    map[string]*LayoutNode
+	boxes    map[string]*LayoutBox
+	depth    int
+	layerGap float64
+}
+
+// LayoutOf lays out the elements of the model in layers along their
+// relations, so relations point downwards where possible. The elements of a
+// boundary are placed next to each other, so boundaries never overlap.
+func LayoutOf(c4Model *C4DiagramModel) *Layout {
+	l := &layouter{
+		layout: &Layout{},
+		root:   &layoutItem{},
+		nodes:  make(map[string]*LayoutNode),
+		boxes:  make(map[string]*LayoutBox),
+	}
+	l.addElements(c4Model)
+	l.assignLayers(c4Model.Relations)
+
+	l.depth = depthOf(l.root) - 1
+	l.layerGap = layoutGap + float64(l.depth)*layoutLayerGapPerDepth
+
+	l.place(l.root, layoutMargin)
+	for i := 0; i < layoutOrderingSweeps; i++ {
+		l.order(l.root, c4Model.Relations)
+		l.place(l.root, layoutMargin)
+	}
+
+	l.addEdges(c4Model.Relations)
+	l.collect(l.root)
+
+	for _, node := range l.layout.Nodes {
+		l.layout.Width = math.Max(l.layout.Width, node.X+node.Width+layoutMargin)
+		l.layout.Height = math.Max(l.layout.Height, node.Bottom()+layoutMargin)
+	}
+	for _, boundary := range l.layout.Boundaries {
+		l.layout.Width = math.Max(l.layout.Width, boundary.X+boundary.Width+layoutMargin)
+		l.layout.Height = math.Max(l.layout.Height, boundary.Bottom()+layoutMargin)
+	}
+
+	return l.layout
+}
+
+// addElements builds the tree of elements from the model.
+func (l *layouter) addElements(c4Model *C4DiagramModel) {
+	for _, person := range c4Model.Persons {
+		l.addNode(l.root, person.ID, person.Title, "person", person.Technology, person.Description, person.Tags)
+	}
+	for _, system := range c4Model.ExternalSystems {
+		l.addNode(l.root, system.ID, system.Title, "external", system.Technology, system.Description, system.Tags)
+	}
+
+	for _, system := range c4Model.Systems {
+		if len(system.Containers) == 0 && len(system.APIs) == 0 {
+			l.addNode(l.root, system.ID, system.Title, "system", system.Technology, system.Description, system.Tags)
+			continue
+		}
+
+		boundary := l.addBoundary(l.root, system.ID, system.Title, "system", system.Tags)
+		for _, container := range system.Containers {
+			l.addContainer(boundary, container)
+		}
+		for _, api := range system.APIs {
+			l.addNode(boundary, api.ID, api.Title, "api", api.Type, api.Description, api.Tags)
+		}
+	}
+
+	for _, node := range c4Model.DeploymentNodes {
+		l.addDeploymentNode(l.root, node)
+	}
+	for _, container := range c4Model.Containers {
+		l.addContainer(l.root, container)
+	}
+	for _, component := range c4Model.Components {
+		l.addNode(l.root, component.ID, component.Title, "component", component.Technology, component.Description, component.Tags)
+	}
+	for _, api := range c4Model.StandaloneAPIs() {
+		l.addNode(l.root, api.ID, api.Title, "api", api.Type, api.Description, api.Tags)
+	}
+}
+
+func (l *layouter) addContainer(parent *layoutItem, container *Container) {
+	if _, ok := l.boxes[container.ID]; ok {
+		return
+	}
+
+	if len(container.Components) == 0 {
+		kind := "container"
+		if container.IsDatabase() {
+			kind = "database"
+		}
+		l.addNode(parent, container.ID, container.Title, kind, container.Technology, container.Description, container.Tags)
+		return
+	}
+
+	boundary := l.addBoundary(parent, container.ID, container.Title, "container", container.Tags)
+	for _, component := range container.Components {
+		kind := "component"
+		if component.IsDatabase() {
+			kind = "database"
+		}
+		l.addNode(boundary, component.ID, component.Title, kind, component.Technology, component.Description, component.Tags)
+	}
+}
+
+func (l *layouter) addDeploymentNode(parent *layoutItem, node *DeploymentNode) {
+	boundary := l.addBoundary(parent, node.ID, node.Title, "deployment", node.Tags)
+	for _, child := range node.Nodes {
+		l.addDeploymentNode(boundary, child)
+	}
+	for _, container := range node.Containers {
+		l.addContainer(boundary, container)
+	}
+}
+
+func (l *layouter) addNode(parent *layoutItem, id string, title string, kind string, technology string, description string, tags []string) {
+	if _, ok := l.boxes[id]; ok {
+		return
+	}
+
+	node := &LayoutNode{
+		LayoutBox:   LayoutBox{Width: layoutNodeWidth, Height: layoutNodeHeight},
+		ID:          id,
+		Title:       title,
+		Kind:        kind,
+		Technology:  technology,
+		Description: description,
+		Tags:        tags,
+	}
+	if parent.boundary != nil {
+		node.Parent = parent.boundary.ID
+	}
+
+	l.nodes[id] = node
+	l.boxes[id] = &node.LayoutBox
+	parent.children = append(parent.children, &layoutItem{node: node})
+}
+
+func (l *layouter) addBoundary(parent *layoutItem, id string, title string, kind string, tags []string) *layoutItem {
+	boundary := &LayoutBoundary{
+		ID:    id,
+		Title: title,
+		Kind:  kind,
+		Tags:  tags,
+	}
+	if parent.boundary != nil {
+		boundary.Parent = parent.boundary.ID
+	}
+
+	item := &layoutItem{boundary: boundary}
+	l.boxes[id] = &boundary.LayoutBox
+	parent.children = append(parent.children, item)
+	return item
+}
+
+// assignLayers places every node one layer below the lowest node with a
+// relation to it, ignoring relations closing cycles.
+func (l *layouter) assignLayers(relations []Relation) {
+	targets := make(map[string][]string)
+	for _, relation := range relations {
+		_, sourceOk := l.nodes[relation.SourceID]
+		_, targetOk := l.nodes[relation.TargetID]
+		if sourceOk && targetOk && relation.SourceID != relation.TargetID {
+			targets[relation.SourceID] = append(targets[relation.SourceID], relation.TargetID)
+		}
+	}
+
+	// drop relations back to a node on the current path of the search
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	acyclic := make(map[string][]string)
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		for _, target := range targets[id] {
+			switch state[target] {
+			case unvisited:
+				acyclic[id] = append(acyclic[id], target)
+				visit(target)
+			case visited:
+				acyclic[id] = append(acyclic[id], target)
+			}
+		}
+		state[id] = visited
+	}
+
+	var ids []string
+	l.walk(l.root, func(node *LayoutNode) {
+		ids = append(ids, node.ID)
+	})
+	for _, id := range ids {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+
+	// longest path in topological order
+	var order []string
+	done := make(map[string]bool)
+	var sortTopological func(id string)
+	sortTopological = func(id string) {
+		done[id] = true
+		for _, target := range acyclic[id] {
+			if !done[target] {
+				sortTopological(target)
+			}
+		}
+		order = append(order, id)
+	}
+	for _, id := range ids {
+		if !done[id] {
+			sortTopological(id)
+		}
+	}
+	slices.Reverse(order)
+
+	for _, id := range order {
+		for _, target := range acyclic[id] {
+			l.nodes[target].layer = max(l.nodes[target].layer, l.nodes[id].layer+1)
+		}
+	}
+}
+
+// place places the item at x and returns its width.
+func (l *layouter) place(item *layoutItem, x float64) float64 {
+	padding := 0.0
+	if item.boundary != nil {
+		padding = layoutBoundaryPadding
+	}
+	innerX := x + padding
+
+	// nodes in columns, centered in each layer
+	layers := make(map[int][]*LayoutNode)
+	columns := 0
+	for _, child := range item.children {
+		if child.node != nil {
+			layers[child.node.layer] = append(layers[child.node.layer], child.node)
+			columns = max(columns, len(layers[child.node.layer]))
+		}
+	}
+	columnsWidth := float64(columns)*(layoutNodeWidth+layoutGap) - layoutGap
+	for layer, nodes := range layers {
+		offset := (columnsWidth - (float64(len(nodes))*(layoutNodeWidth+layoutGap) - layoutGap)) / 2
+		for i, node := range nodes {
+			node.X = innerX + offset + float64(i)*(layoutNodeWidth+layoutGap)
+			node.Y = l.layerY(layer)
+		}
+	}
+
+	// nested boundaries next to the nodes
+	cursor := innerX
+	if columns > 0 {
+		cursor += columnsWidth + layoutGap
+	}
+	for _, child := range item.children {
+		if child.boundary != nil {
+			cursor += l.place(child, cursor) + layoutGap
+		}
+	}
+	innerWidth := math.Max(cursor-layoutGap-innerX, 0)
+
+	if item.boundary == nil {
+		return innerWidth
+	}
+
+	boundary := item.boundary
+	boundary.X = x
+	boundary.Width = math.Max(innerWidth, layoutNodeWidth) + 2*padding
+
+	top, bottom := math.Inf(1), math.Inf(-1)
+	for _, child := range item.children {
+		box := child.box()
+		top = math.Min(top, box.Y)
+		bottom = math.Max(bottom, box.Bottom())
+	}
+	if len(item.children) == 0 {
+		top, bottom = l.layerY(0), l.layerY(0)+layoutNodeHeight
+	}
+	boundary.Y = top - padding - layoutBoundaryTitle
+	boundary.Height = bottom - boundary.Y + padding
+
+	return boundary.Width
+}
+
+func (l *layouter) layerY(layer int) float64 {
+	top := layoutMargin + float64(l.depth)*(layoutBoundaryPadding+layoutBoundaryTitle)
+	return top + float64(layer)*(layoutNodeHeight+l.layerGap)
+}
+
+// order sorts the nodes of every layer and the nested boundaries by the
+// mean position of the nodes they are related to.
+func (l *layouter) order(item *layoutItem, relations []Relation) {
+	neighbours := make(map[string][]string)
+	for _, relation := range relations {
+		neighbours[relation.SourceID] = append(neighbours[relation.SourceID], relation.TargetID)
+		neighbours[relation.TargetID] = append(neighbours[relation.TargetID], relation.SourceID)
+	}
+
+	var sortChildren func(item *layoutItem)
+	sortChildren = func(item *layoutItem) {
+		barycenters := make(map[*layoutItem]float64)
+		for _, child := range item.children {
+			var sum float64
+			var count int
+			l.walk(child, func(node *LayoutNode) {
+				for _, neighbour := range neighbours[node.ID] {
+					if box, ok := l.boxes[neighbour]; ok {
+						sum += box.CenterX()
+						count++
+					}
+				}
+			})
+			barycenters[child] = child.box().CenterX()
+			if count > 0 {
+				barycenters[child] = sum / float64(count)
+			}
+		}
+
+		sort.SliceStable(item.children, func(i, j int) bool {
+			return barycenters[item.children[i]] < barycenters[item.children[j]]
+		})
+
+		for _, child := range item.children {
+			if child.boundary != nil {
+				sortChildren(child)
+			}
+		}
+	}
+	sortChildren(item)
+}
+
+func (l *layouter) addEdges(relations []Relation) {
+	for _, relation := range relations {
+		source, sourceOk := l.boxes[relation.SourceID]
+		target, targetOk := l.boxes[relation.TargetID]
+		if !sourceOk || !targetOk || source == target {
+			continue
+		}
+
+		l.layout.Edges = append(l.layout.Edges, &LayoutEdge{
+			SourceID:   relation.SourceID,
+			TargetID:   relation.TargetID,
+			Label:      relation.Label,
+			Technology: relation.Technology,
+			Tags:       relation.Tags,
+			Start:      borderPoint(source, target),
+			End:        borderPoint(target, source),
+		})
+	}
+}
+
+// collect adds all nodes and boundaries to the layout, outer boundaries
+// first.
+func (l *layouter) collect(item *layoutItem) {
+	for _, child := range item.children {
+		if child.node != nil {
+			l.layout.Nodes = append(l.layout.Nodes, child.node)
+			continue
+		}
+		l.layout.Boundaries = append(l.layout.Boundaries, child.boundary)
+		l.collect(child)
+	}
+}
+
+// walk calls fn for all nodes in the tree of the item.
+func (l *layouter) walk(item *layoutItem, fn func(node *LayoutNode)) {
+	if item.node != nil {
+		fn(item.node)
+	}
+	for _, child := range item.children {
+		l.walk(child, fn)
+	}
+}
+
+func (item *layoutItem) box() *LayoutBox {
+	if item.node != nil {
+		return &item.node.LayoutBox
+	}
+	return &item.boundary.LayoutBox
+}
+
+func depthOf(item *layoutItem) int {
+	depth := 0
+	for _, child := range item.children {
+		if child.boundary != nil {
+			depth = max(depth, depthOf(child))
+		}
+	}
+	return depth + 1
+}
+
+// borderPoint is the point where the line from the center of the box to
+// the center of the other box leaves the box.
+func borderPoint(box *LayoutBox, other *LayoutBox) LayoutPoint {
+	cx, cy := box.CenterX(), box.CenterY()
+	dx, dy := other.CenterX()-cx, other.CenterY()-cy
+	if dx == 0 && dy == 0 {
+		return LayoutPoint{X: cx, Y: cy}
+	}
+
+	t := math.Inf(1)
+	if dx != 0 {
+		t = math.Min(t, box.Width/2/math.Abs(dx))
+	}
+	if dy != 0 {
+		t = math.Min(t, box.Height/2/math.Abs(dy))
+	}
+	t = math.Min(t, 1)
+
+	return LayoutPoint{X: cx + t*dx, Y: cy + t*dy}
+}
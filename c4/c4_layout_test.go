@@ -0,0 +1,105 @@
+package c4
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestLayoutOfLayersAlongRelations(t *testing.T) {
+	is := is.New(t)
+
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "customer", Title: "Customer", Type: "person"})
+	m.AddSystem(&System{ID: "s2", Label: "shop", Title: "Shop"})
+	m.AddSystem(&System{ID: "s3", Label: "psp", Title: "PSP", Type: "external"})
+	m.AddRelation(Relation{SourceID: "s1", TargetID: "s2"})
+	m.AddRelation(Relation{SourceID: "s2", TargetID: "s3"})
+	m.AddRelation(Relation{SourceID: "s3", TargetID: "s1"})
+
+	layout := LayoutOf(m)
+
+	nodes := make(map[string]*LayoutNode)
+	for _, node := range layout.Nodes {
+		nodes[node.ID] = node
+	}
+	is.Equal(len(nodes), 3)
+	is.True(nodes["s1"].Y < nodes["s2"].Y)
+	is.True(nodes["s2"].Y < nodes["s3"].Y)
+	is.Equal(nodes["s1"].Kind, "person")
+	is.Equal(nodes["s3"].Kind, "external")
+
+	is.Equal(len(layout.Edges), 3)
+	is.Equal(layout.Edges[0].Start.Y, nodes["s1"].Bottom())
+	is.Equal(layout.Edges[0].End.Y, nodes["s2"].Y)
+	is.True(layout.Width > 0)
+	is.True(layout.Height > nodes["s3"].Bottom())
+}
+
+func TestLayoutOfBoundaries(t *testing.T) {
+	is := is.New(t)
+
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "shop", Title: "Shop"})
+	m.AddSystem(&System{ID: "s2", Label: "crm", Title: "CRM"})
+	m.AddContainer(&Container{ID: "c1", Label: "shop-api", System: "shop"})
+	m.AddContainer(&Container{ID: "c2", Label: "shop-db", System: "shop", Type: "database"})
+	m.AddContainer(&Container{ID: "c3", Label: "crm-api", System: "crm"})
+	m.AddRelation(Relation{SourceID: "c1", TargetID: "c2"})
+	m.AddRelation(Relation{SourceID: "c1", TargetID: "c3"})
+	m.PostProcess()
+
+	layout := LayoutOf(m)
+
+	is.Equal(len(layout.Boundaries), 2)
+	is.Equal(len(layout.Nodes), 3)
+
+	boxes := make(map[string]LayoutBox)
+	for _, boundary := range layout.Boundaries {
+		boxes[boundary.ID] = boundary.LayoutBox
+	}
+	for _, node := range layout.Nodes {
+		boxes[node.ID] = node.LayoutBox
+		is.True(node.Parent != "")
+	}
+
+	is.True(contains(boxes["s1"], boxes["c1"]))
+	is.True(contains(boxes["s1"], boxes["c2"]))
+	is.True(contains(boxes["s2"], boxes["c3"]))
+	is.True(!overlaps(boxes["s1"], boxes["s2"]))
+}
+
+func TestLayoutOfDeploymentNodes(t *testing.T) {
+	is := is.New(t)
+
+	m := &C4DiagramModel{}
+	m.Deploy(&Container{ID: "c1", Label: "orders-api"}, []string{"aws-prod", "eks-cluster"}, nil)
+	m.Deploy(&Container{ID: "c2", Label: "orders-db"}, []string{"aws-prod", "rds"}, nil)
+
+	layout := LayoutOf(m)
+
+	is.Equal(len(layout.Boundaries), 3)
+	is.Equal(layout.Boundaries[0].Kind, "deployment")
+	is.Equal(layout.Boundaries[1].Parent, layout.Boundaries[0].ID)
+	is.True(contains(layout.Boundaries[0].LayoutBox, layout.Boundaries[1].LayoutBox))
+	is.True(contains(layout.Boundaries[0].LayoutBox, layout.Boundaries[2].LayoutBox))
+	is.True(!overlaps(layout.Boundaries[1].LayoutBox, layout.Boundaries[2].LayoutBox))
+}
+
+func TestBorderPoint(t *testing.T) {
+	is := is.New(t)
+
+	box := &LayoutBox{X: 0, Y: 0, Width: 100, Height: 50}
+
+	is.Equal(borderPoint(box, &LayoutBox{X: 0, Y: 200, Width: 100, Height: 50}), LayoutPoint{X: 50, Y: 50})
+	is.Equal(borderPoint(box, &LayoutBox{X: 300, Y: 0, Width: 100, Height: 50}), LayoutPoint{X: 100, Y: 25})
+}
+
+func contains(outer LayoutBox, inner LayoutBox) bool {
+	return outer.X < inner.X && outer.Y < inner.Y &&
+		inner.X+inner.Width < outer.X+outer.Width && inner.Bottom() < outer.Bottom()
+}
+
+func overlaps(a LayoutBox, b LayoutBox) bool {
+	return a.X < b.X+b.Width && b.X < a.X+a.Width && a.Y < b.Bottom() && b.Y < a.Bottom()
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+	"github.io/remast/c4stage/shared"
 )
 
 var _ C4Repository = (*C4EntityNeo4j)(nil)
@@ -18,19 +20,25 @@ type C4EntityNeo4j struct {
 func (r *C4EntityNeo4j) ContainerDiagram(
 	ctx context.Context,
 	name string,
+	filter DiagramFilter,
 ) (*C4DiagramModel, error) {
 	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
-		`
-		MATCH (s:System{name: $name})-[r:CONTAINS]-(c:Component)
-		OPTIONAL MATCH (s)-[:CONTAINS]-(d:Component)-[otherSystemDep:DEPENDS_ON]-(otherSystem:System)
-		OPTIONAL MATCH (d)-[extSystemDep:DEPENDS_ON]-(extSystem:System{type:"external"}) 
-		OPTIONAL MATCH (c)-[personDep:DEPENDS_ON]-(person:System{type:"person"}) 
-		OPTIONAL MATCH (c)-[containerDep:DEPENDS_ON]-(e:Component{system: $name})
-		RETURN s,r,c,d,containerDep,e,otherSystemDep,otherSystem,extSystemDep,extSystem,personDep,person
-		`,
-		map[string]any{
-			"name": name,
-		}, neo4j.EagerResultTransformer)
+		fmt.Sprintf(`
+		MATCH (s:System{name: $name, snapshot: $snapshot})-[:CONTAINS]-(c:Component)
+		WHERE %v
+		WITH s, collect(c) AS containers
+		UNWIND containers AS c
+		MATCH (s)-[r:CONTAINS]-(c)
+		OPTIONAL MATCH (c)-[containerDep:DEPENDS_ON]->(e:Component)
+		WHERE e IN containers
+		OPTIONAL MATCH (c)-[otherSystemDep:DEPENDS_ON]-(otherSystem:System)
+		WHERE otherSystem.name <> $name AND %v
+		RETURN s,r,c,containerDep,e,otherSystemDep,otherSystem
+		`, filter.Where("c", "Component"), filter.WhereNotExcluded("otherSystem")),
+		filter.Params(map[string]any{
+			"snapshot": shared.SnapshotFromContext(ctx),
+			"name":     name,
+		}), neo4j.EagerResultTransformer)
 	if err != nil {
 		return nil, err
 	}
@@ -67,19 +75,86 @@ func (r *C4EntityNeo4j) ContainerDiagram(
 	return c4Model, nil
 }
 
+// ComponentDiagram builds the components of the container of the system
+// together with the containers and systems the components depend on or are
+// depended on by.
+func (r *C4EntityNeo4j) ComponentDiagram(
+	ctx context.Context,
+	system string,
+	container string,
+) (*C4DiagramModel, error) {
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (s:System{name: $system, snapshot: $snapshot})-[:CONTAINS]->(c:Component{name: $container})-[:CONTAINS]->(sc:SubComponent)
+		OPTIONAL MATCH (sc)-[dep:DEPENDS_ON]-(other:SubComponent|Component|System)
+		OPTIONAL MATCH (otherSystem:System)-[:CONTAINS]->(other)
+		RETURN s,c,sc,dep,other,otherSystem
+		`,
+		map[string]any{
+			"snapshot":  shared.SnapshotFromContext(ctx),
+			"system":    system,
+			"container": container,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return nil, err
+	}
+
+	c4Model := &C4DiagramModel{}
+
+	var relations []Relation
+	for _, record := range result.Records {
+		for _, recordValue := range record.Values {
+			switch node := recordValue.(type) {
+			case dbtype.Relationship:
+				relations = append(relations, readRelation(node))
+			case dbtype.Node:
+				if slices.Contains(node.Labels, "System") {
+					c4Model.AddSystem(readSystem(node))
+				} else if slices.Contains(node.Labels, "Component") {
+					c4Model.AddContainer(readContainer(node))
+				} else if slices.Contains(node.Labels, "SubComponent") {
+					component := readComponent(node)
+					if component.Container == container {
+						c4Model.AddComponent(component)
+					}
+				}
+			}
+		}
+	}
+
+	// only keep relations between elements of the diagram
+	for _, relation := range relations {
+		if c4Model.HasElement(relation.SourceID) && c4Model.HasElement(relation.TargetID) {
+			c4Model.AddRelation(relation)
+		}
+	}
+
+	c4Model.PostProcess()
+
+	return c4Model, nil
+}
+
 func (r *C4EntityNeo4j) SystemLandscapeContainerDiagram(
 	ctx context.Context,
+	filter DiagramFilter,
 ) (*C4DiagramModel, error) {
 	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
-		`		
-		MATCH (e:Component)-[r:DEPENDS_ON]-(m:Component)
-		MATCH (c1:Component)-[l:CONTAINS]-(s1:System)
-		OPTIONAL MATCH (c1:Component)-[extSystemDep:DEPENDS_ON]-(extSystem:System{type:"external"})
-		OPTIONAL MATCH (c1:Component)-[personDep:DEPENDS_ON]-(person:System{type:"person"})
-		RETURN e,r,m,c1,l,s1,extSystemDep,extSystem,personDep,person
+		fmt.Sprintf(`
+		MATCH (:System{snapshot: $snapshot})-[:CONTAINS]-(c:Component)
+		WHERE %v
+		WITH collect(c) AS containers
+		UNWIND containers AS c1
+		MATCH (c1)-[l:CONTAINS]-(s1:System)
+		OPTIONAL MATCH (c1)-[r:DEPENDS_ON]->(m:Component)
+		WHERE m IN containers
+		OPTIONAL MATCH (c1)-[systemDep:DEPENDS_ON]-(system:System)
+		WHERE system.type IN ["external", "person"] AND %v
+		RETURN c1,l,s1,r,m,systemDep,system
 		LIMIT 10000
-		`,
-		map[string]any{}, neo4j.EagerResultTransformer)
+		`, filter.Where("c", "Component"), filter.WhereNotExcluded("system")),
+		filter.Params(map[string]any{
+			"snapshot": shared.SnapshotFromContext(ctx),
+		}), neo4j.EagerResultTransformer)
 
 	if err != nil {
 		return nil, err
@@ -112,16 +187,210 @@ func (r *C4EntityNeo4j) SystemLandscapeContainerDiagram(
 	return c4Model, nil
 }
 
-func (r *C4EntityNeo4j) SystemLandscapeDiagram(
+// SystemContextDiagram builds the context of the system, all systems and
+// persons depending on it and all systems it depends on. The relations keep
+// their labels and are tagged `inbound` or `outbound` by their direction.
+func (r *C4EntityNeo4j) SystemContextDiagram(
 	ctx context.Context,
+	name string,
 ) (*C4DiagramModel, error) {
 	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
 		`
-		MATCH (s:System) 
-		OPTIONAL MATCH (:System)-[r]-(:System)
-		RETURN s,r LIMIT 3000
+		MATCH (s:System{name: $name, snapshot: $snapshot})
+		OPTIONAL MATCH (s)-[outbound:DEPENDS_ON]->(target:System)
+		WHERE target <> s
+		OPTIONAL MATCH (source:System)-[inbound:DEPENDS_ON]->(s)
+		WHERE source <> s
+		RETURN s,outbound,target,inbound,source
+		`,
+		map[string]any{
+			"snapshot": shared.SnapshotFromContext(ctx),
+			"name":     name,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return nil, err
+	}
+
+	c4Model := &C4DiagramModel{}
+
+	for _, record := range result.Records {
+		for _, key := range record.Keys {
+			recordValue, _ := record.Get(key)
+			switch node := recordValue.(type) {
+			case dbtype.Relationship:
+				relation := readRelation(node)
+				if key == "inbound" {
+					relation.AddTag("inbound")
+				} else {
+					relation.AddTag("outbound")
+				}
+				c4Model.AddRelation(relation)
+			case dbtype.Node:
+				c4Model.AddSystem(readSystem(node))
+			}
+		}
+	}
+
+	return c4Model, nil
+}
+
+// DeploymentDiagram builds the deployment of the containers of the system
+// in the given environment on their deployment nodes.
+func (r *C4EntityNeo4j) DeploymentDiagram(
+	ctx context.Context,
+	system string,
+	env string,
+) (*C4DiagramModel, error) {
+	snapshot := shared.SnapshotFromContext(ctx)
+
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (n:DeploymentNode{snapshot: $snapshot})
+		RETURN n
+		`,
+		map[string]any{
+			"snapshot": snapshot,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]*DeploymentNode)
+	parents := make(map[string]string)
+	for _, record := range result.Records {
+		node, _, err := neo4j.GetRecordValue[dbtype.Node](record, "n")
+		if err != nil {
+			return nil, err
+		}
+
+		deploymentNode := readDeploymentNode(node)
+		known[deploymentNode.Label] = deploymentNode
+		if path, ok := readDeployments(node)[env]; ok {
+			parents[deploymentNode.Label] = path
+		}
+	}
+
+	result, err = neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (s:System{name: $system, snapshot: $snapshot})-[:CONTAINS]->(c:Component)
+		OPTIONAL MATCH (c)-[dep:DEPENDS_ON]->(:Component{system: $system, snapshot: $snapshot})
+		RETURN c, dep
 		`,
-		map[string]any{}, neo4j.EagerResultTransformer)
+		map[string]any{
+			"snapshot": snapshot,
+			"system":   system,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return nil, err
+	}
+
+	c4Model := &C4DiagramModel{}
+
+	var relations []Relation
+	for _, record := range result.Records {
+		node, _, err := neo4j.GetRecordValue[dbtype.Node](record, "c")
+		if err != nil {
+			return nil, err
+		}
+
+		path, ok := readDeployments(node)[env]
+		container := readContainer(node)
+		if ok && !c4Model.HasElement(container.ID) {
+			c4Model.AddContainer(container)
+			c4Model.Deploy(container, ResolveDeploymentPath(path, parents), known)
+		}
+
+		dep, isNil, err := neo4j.GetRecordValue[dbtype.Relationship](record, "dep")
+		if err == nil && !isNil {
+			relations = append(relations, readRelation(dep))
+		}
+	}
+
+	// only keep relations between deployed containers
+	for _, relation := range relations {
+		if c4Model.HasElement(relation.SourceID) && c4Model.HasElement(relation.TargetID) {
+			c4Model.AddRelation(relation)
+		}
+	}
+
+	return c4Model, nil
+}
+
+// APIDiagram builds the API with the system and containers providing it and
+// all containers consuming it within their systems.
+func (r *C4EntityNeo4j) APIDiagram(
+	ctx context.Context,
+	name string,
+) (*C4DiagramModel, error) {
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (a:API{name: $name, snapshot: $snapshot})
+		OPTIONAL MATCH (provider:System|Component)-[provides:PROVIDES]->(a)
+		OPTIONAL MATCH (providerSystem:System{name: provider.system, snapshot: $snapshot})
+		OPTIONAL MATCH (consumer:Component)-[consumes:CONSUMES]->(a)
+		OPTIONAL MATCH (consumerSystem:System)-[:CONTAINS]->(consumer)
+		RETURN a,provider,provides,providerSystem,consumer,consumes,consumerSystem
+		`,
+		map[string]any{
+			"snapshot": shared.SnapshotFromContext(ctx),
+			"name":     name,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return nil, err
+	}
+
+	c4Model := &C4DiagramModel{}
+
+	var relations []Relation
+	for _, record := range result.Records {
+		for _, recordValue := range record.Values {
+			switch node := recordValue.(type) {
+			case dbtype.Relationship:
+				relations = append(relations, readRelation(node))
+			case dbtype.Node:
+				if slices.Contains(node.Labels, "System") {
+					c4Model.AddSystem(readSystem(node))
+				} else if slices.Contains(node.Labels, "Component") {
+					c4Model.AddContainer(readContainer(node))
+				} else if slices.Contains(node.Labels, "API") {
+					c4Model.AddAPI(readAPI(node))
+				}
+			}
+		}
+	}
+
+	c4Model.PostProcess()
+
+	// systems providing the API are drawn as its boundary
+	for _, relation := range relations {
+		providedWithin := slices.ContainsFunc(c4Model.Systems, func(system *System) bool {
+			return system.ID == relation.SourceID && len(system.APIs) > 0
+		})
+		if !providedWithin {
+			c4Model.AddRelation(relation)
+		}
+	}
+
+	return c4Model, nil
+}
+
+func (r *C4EntityNeo4j) SystemLandscapeDiagram(
+	ctx context.Context,
+	filter DiagramFilter,
+) (*C4DiagramModel, error) {
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		fmt.Sprintf(`
+		MATCH (s:System{snapshot: $snapshot})
+		WHERE %v
+		WITH collect(s) AS systems
+		UNWIND systems AS s
+		OPTIONAL MATCH (s)-[r]->(o:System)
+		WHERE o IN systems
+		RETURN s,r LIMIT 3000
+		`, filter.Where("s", "System")),
+		filter.Params(map[string]any{
+			"snapshot": shared.SnapshotFromContext(ctx),
+		}), neo4j.EagerResultTransformer)
 	if err != nil {
 		return nil, err
 	}
@@ -196,11 +465,114 @@ func readContainer(node dbtype.Node) *Container {
 	return container
 }
 
+func readComponent(node dbtype.Node) *Component {
+	component := &Component{
+		ID:          AsID(node.ElementId),
+		Type:        fmt.Sprintf("%v", node.Props["type"]),
+		Label:       fmt.Sprintf("%v", node.Props["name"]),
+		Title:       fmt.Sprintf("%v", node.Props["title"]),
+		Description: fmt.Sprintf("%v", node.Props["description"]),
+		Container:   fmt.Sprintf("%v", node.Props["container"]),
+	}
+
+	if node.Props["tags"] != nil {
+		tagsRaw := node.Props["tags"].([]any)
+		for _, tagRaw := range tagsRaw {
+			component.Tags = append(component.Tags, fmt.Sprintf("%v", tagRaw))
+		}
+	}
+
+	if component.Title == "" {
+		component.Title = component.Label
+	}
+
+	lifecycle := fmt.Sprintf("%v", node.Props["lifecycle"])
+	component.AddTag(lifecycle)
+
+	return component
+}
+
+func readAPI(node dbtype.Node) *API {
+	api := &API{
+		ID:          AsID(node.ElementId),
+		Label:       fmt.Sprintf("%v", node.Props["name"]),
+		Title:       fmt.Sprintf("%v", node.Props["title"]),
+		Description: fmt.Sprintf("%v", node.Props["description"]),
+		Type:        fmt.Sprintf("%v", node.Props["type"]),
+		System:      fmt.Sprintf("%v", node.Props["system"]),
+	}
+
+	if api.Title == "" {
+		api.Title = api.Label
+	}
+
+	lifecycle := fmt.Sprintf("%v", node.Props["lifecycle"])
+	api.AddTag(lifecycle)
+
+	return api
+}
+
+func readDeploymentNode(node dbtype.Node) *DeploymentNode {
+	deploymentNode := &DeploymentNode{
+		Label:       fmt.Sprintf("%v", node.Props["name"]),
+		Title:       fmt.Sprintf("%v", node.Props["title"]),
+		Technology:  fmt.Sprintf("%v", node.Props["type"]),
+		Description: fmt.Sprintf("%v", node.Props["description"]),
+	}
+
+	if deploymentNode.Title == "" {
+		deploymentNode.Title = deploymentNode.Label
+	}
+
+	return deploymentNode
+}
+
+// readDeployments reads the paths of deployment nodes by environment.
+func readDeployments(node dbtype.Node) map[string]string {
+	deployments := make(map[string]string)
+	if deploymentsRaw, ok := node.Props["deployments"].([]any); ok {
+		for _, deploymentRaw := range deploymentsRaw {
+			env, path, ok := strings.Cut(fmt.Sprintf("%v", deploymentRaw), "=")
+			if ok {
+				deployments[env] = path
+			}
+		}
+	}
+	return deployments
+}
+
 func readRelation(node dbtype.Relationship) Relation {
 	relation := Relation{
 		SourceID: AsID(node.StartElementId),
 		TargetID: AsID(node.EndElementId),
 		Label:    AsRelation(node.Type),
 	}
+
+	// relations through APIs carry the API and how it is used
+	apiName, _ := node.Props["apiName"].(string)
+	apiType, _ := node.Props["apiType"].(string)
+	description, _ := node.Props["description"].(string)
+	technology, _ := node.Props["technology"].(string)
+	if technology == "" {
+		technology = AsTechnology(apiType)
+	}
+	if apiName != "" {
+		relation.AddUsage(Usage{
+			API:        apiName,
+			Label:      AsUsage(apiName, description),
+			Technology: technology,
+		})
+	} else {
+		if description != "" {
+			relation.Label = description
+		}
+		relation.Technology = technology
+	}
+
+	violations, ok := node.Props["violations"].([]any)
+	if ok && len(violations) > 0 {
+		relation.AddTag("violation")
+	}
+
 	return relation
 }
@@ -0,0 +1,138 @@
+package c4
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+)
+
+// FindFlows finds all flows of the system. Flows are maintained in c4stage
+// and not part of the imported catalog, so they are not scoped to a snapshot
+// and survive imports.
+func (r *C4EntityNeo4j) FindFlows(
+	ctx context.Context,
+	system string,
+) ([]Flow, error) {
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (f:Flow{system: $system})
+		RETURN f ORDER BY f.name
+		`,
+		map[string]any{
+			"system": system,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return nil, err
+	}
+
+	flows := make([]Flow, 0, len(result.Records))
+	for _, record := range result.Records {
+		node, _, err := neo4j.GetRecordValue[dbtype.Node](record, "f")
+		if err != nil {
+			return nil, err
+		}
+
+		flow, err := readFlow(node)
+		if err != nil {
+			return nil, err
+		}
+		flows = append(flows, *flow)
+	}
+
+	return flows, nil
+}
+
+func (r *C4EntityNeo4j) FindFlow(
+	ctx context.Context,
+	system string,
+	name string,
+) (*Flow, error) {
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (f:Flow{system: $system, name: $name})
+		RETURN f
+		`,
+		map[string]any{
+			"system": system,
+			"name":   name,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Records) == 0 {
+		return nil, nil
+	}
+
+	node, _, err := neo4j.GetRecordValue[dbtype.Node](result.Records[0], "f")
+	if err != nil {
+		return nil, err
+	}
+
+	return readFlow(node)
+}
+
+func (r *C4EntityNeo4j) SaveFlow(
+	ctx context.Context,
+	flow *Flow,
+) error {
+	steps, err := json.Marshal(flow.Steps)
+	if err != nil {
+		return err
+	}
+
+	_, err = neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MERGE (f:Flow{system: $system, name: $name})
+		SET f.title = $title,
+			f.description = $description,
+			f.steps = $steps
+		`,
+		map[string]any{
+			"system":      flow.System,
+			"name":        flow.Name,
+			"title":       flow.Title,
+			"description": flow.Description,
+			"steps":       string(steps),
+		}, neo4j.EagerResultTransformer)
+	return err
+}
+
+func (r *C4EntityNeo4j) DeleteFlow(
+	ctx context.Context,
+	system string,
+	name string,
+) error {
+	_, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (f:Flow{system: $system, name: $name})
+		DELETE f
+		`,
+		map[string]any{
+			"system": system,
+			"name":   name,
+		}, neo4j.EagerResultTransformer)
+	return err
+}
+
+func readFlow(node dbtype.Node) (*Flow, error) {
+	flow := &Flow{
+		System: fmt.Sprintf("%v", node.Props["system"]),
+		Name:   fmt.Sprintf("%v", node.Props["name"]),
+	}
+	flow.Title, _ = node.Props["title"].(string)
+	flow.Description, _ = node.Props["description"].(string)
+
+	steps, ok := node.Props["steps"].(string)
+	if ok && steps != "" {
+		err := json.Unmarshal([]byte(steps), &flow.Steps)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return flow, nil
+}
@@ -0,0 +1,41 @@
+package c4
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+)
+
+func TestReadRelationThroughAPI(t *testing.T) {
+	is := is.New(t)
+
+	relation := readRelation(dbtype.Relationship{
+		StartElementId: "4:a:1",
+		EndElementId:   "4:a:2",
+		Type:           "DEPENDS_ON",
+		Props: map[string]any{
+			"apiName":     "orders-api",
+			"apiType":     "openapi",
+			"description": "places orders",
+		},
+	})
+
+	is.Equal(relation.SourceID, "4a1")
+	is.Equal(relation.Label, "places orders via orders-api")
+	is.Equal(relation.Technology, "REST/JSON")
+}
+
+func TestReadRelationWithTechnology(t *testing.T) {
+	is := is.New(t)
+
+	relation := readRelation(dbtype.Relationship{
+		Type: "CONSUMES",
+		Props: map[string]any{
+			"technology": "Kafka",
+		},
+	})
+
+	is.Equal(relation.Label, "uses")
+	is.Equal(relation.Technology, "Kafka")
+}
@@ -0,0 +1,174 @@
+package c4
+
+import (
+	"io"
+	"log"
+	"text/template"
+)
+
+// D2_TPL_C4 renders a model as D2 diagram, with boundaries as containers.
+const D2_TPL_C4 = `
+{{- define "d2Container" }}
+{{- if .Components }}
+  {{ .ID }}: {{ quote (label .Title (stereotype "Container" .Label) "") }} {
+    style.stroke: "#444444"
+    style.stroke-dash: 3
+    style.fill: transparent
+	{{- range .Components }}
+    {{ .ID }}: {{ quote (label .Title (stereotype "Component" .Technology) .Description) }} {
+      shape: {{ if .IsDatabase }}cylinder{{ else }}rectangle{{ end }}
+      style.fill: {{ quote (elementColor "component" .Tags) }}
+      style.font-color: "#000000"
+    }
+	{{- end }}
+  }
+{{- else if .IsDatabase }}
+  {{ .ID }}: {{ quote (label .Title (stereotype "Container" .Label) .Description) }} {
+    shape: cylinder
+    style.fill: {{ quote (elementColor "database" .Tags) }}
+    style.font-color: "#ffffff"
+  }
+{{- else }}
+  {{ .ID }}: {{ quote (label .Title (stereotype "Container" .Label) .Description) }} {
+    style.fill: {{ quote (elementColor "container" .Tags) }}
+    style.font-color: "#ffffff"
+  }
+{{- end }}
+{{- end }}
+{{- define "d2DeploymentNode" }}
+{{ .ID }}: {{ quote (label .Title (stereotype "Deployment Node" .Technology) "") }} {
+  style.stroke: "#444444"
+  style.fill: transparent
+	{{- range .Nodes }}
+	{{- template "d2DeploymentNode" . }}
+	{{- end }}
+	{{- range .Containers }}
+	{{- template "d2Container" . }}
+	{{- end }}
+}
+{{- end -}}
+direction: down
+
+{{- range .Persons }}
+{{ .ID }}: {{ quote (label .Title (stereotype "Person" "") .Description) }} {
+  shape: person
+  style.fill: {{ quote (elementColor "person" .Tags) }}
+  style.font-color: "#ffffff"
+}
+{{- end }}
+
+{{- range .ExternalSystems }}
+{{ .ID }}: {{ quote (label .Title (stereotype "External System" "") .Description) }} {
+  style.fill: {{ quote (elementColor "external" .Tags) }}
+  style.font-color: "#ffffff"
+}
+{{- end }}
+
+{{- range .Systems }}
+{{- if and (not .Containers) (not .APIs) }}
+{{ .ID }}: {{ quote (label .Title (stereotype "Software System" "") .Description) }} {
+  style.fill: {{ quote (elementColor "system" .Tags) }}
+  style.font-color: "#ffffff"
+}
+{{- else }}
+{{ .ID }}: {{ quote (label .Title (stereotype "System" "") "") }} {
+  style.stroke: "#444444"
+  style.stroke-dash: 3
+  style.fill: transparent
+	{{- range .Containers }}
+	{{- template "d2Container" . }}
+	{{- end }}
+	{{- range .APIs }}
+  {{ .ID }}: {{ quote (label .Title (stereotype "API" .Type) .Description) }} {
+    shape: hexagon
+    style.fill: {{ quote (elementColor "api" .Tags) }}
+    style.font-color: "#ffffff"
+  }
+	{{- end }}
+}
+{{- end }}
+{{- end }}
+
+{{- range .StandaloneAPIs }}
+{{ .ID }}: {{ quote (label .Title (stereotype "API" .Type) .Description) }} {
+  shape: hexagon
+  style.fill: {{ quote (elementColor "api" .Tags) }}
+  style.font-color: "#ffffff"
+}
+{{- end }}
+
+{{- range .DeploymentNodes }}
+{{- template "d2DeploymentNode" . }}
+{{- end }}
+
+{{- range .Relations }}
+{{ $.D2Path .SourceID }} -> {{ $.D2Path .TargetID }}: {{ quote (label .Label (relationTechnology .Technology) "") }} {
+  style.stroke: {{ quote (relationColor .Tags) }}
+  style.stroke-dash: 3
+}
+{{- end }}
+`
+
+type d2Exporter struct {
+	template *template.Template
+}
+
+func newD2Exporter() *d2Exporter {
+	t, err := template.New("").Funcs(dotFuncs).Parse(D2_TPL_C4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return &d2Exporter{
+		template: t,
+	}
+}
+
+// ExportToD2 exports the model as D2 diagram.
+func (e *d2Exporter) ExportToD2(c4Model *C4DiagramModel, w io.Writer) error {
+	return e.template.Execute(w, c4Model)
+}
+
+// D2Path returns the path of the element through the boundaries containing
+// it, like `shop.api`, as D2 refers to nested elements by their path.
+func (c4Model *C4DiagramModel) D2Path(id string) string {
+	for _, system := range c4Model.Systems {
+		for _, container := range system.Containers {
+			if container.ID == id {
+				return system.ID + "." + id
+			}
+			for _, component := range container.Components {
+				if component.ID == id {
+					return system.ID + "." + container.ID + "." + id
+				}
+			}
+		}
+		for _, api := range system.APIs {
+			if api.ID == id {
+				return system.ID + "." + id
+			}
+		}
+	}
+
+	if path, ok := deploymentPath(c4Model.DeploymentNodes, id); ok {
+		return path
+	}
+	return id
+}
+
+func deploymentPath(nodes []*DeploymentNode, id string) (string, bool) {
+	for _, node := range nodes {
+		if node.ID == id {
+			return id, true
+		}
+		for _, container := range node.Containers {
+			if container.ID == id {
+				return node.ID + "." + id, true
+			}
+		}
+		if path, ok := deploymentPath(node.Nodes, id); ok {
+			return node.ID + "." + path, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package c4
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestExportToD2(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	e := newD2Exporter()
+
+	sw := bytes.NewBufferString("")
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "customer", Title: "Customer", Type: "person"})
+	m.AddSystem(&System{ID: "s2", Label: "shop", Title: "Shop"})
+	m.AddContainer(&Container{ID: "c1", Label: "shop-api", Title: "Shop API", System: "shop"})
+	m.AddContainer(&Container{ID: "c2", Label: "shop-db", Title: "Shop DB", System: "shop", Type: "database"})
+	m.AddRelation(Relation{SourceID: "s1", TargetID: "c1", Label: "orders", Technology: "REST/JSON"})
+	m.AddRelation(Relation{SourceID: "c1", TargetID: "c2", Label: "depends on"})
+	m.PostProcess()
+
+	// Act
+	err := e.ExportToD2(m, sw)
+	d2 := sw.String()
+
+	// Assert
+	is.NoErr(err)
+	is.True(strings.HasPrefix(d2, "direction: down\n"))
+	is.True(strings.Contains(d2, "s1: \"Customer\\n[Person]\" {\n  shape: person"))
+	is.True(strings.Contains(d2, "s2: \"Shop\\n[System]\" {"))
+	is.True(strings.Contains(d2, "  c2: \"Shop DB\\n[Container: shop-db]\" {\n    shape: cylinder"))
+	is.True(strings.Contains(d2, "s1 -> s2.c1: \"orders\\n[REST/JSON]\" {"))
+	is.True(strings.Contains(d2, "s2.c1 -> s2.c2: \"depends on\" {"))
+}
+
+func TestD2Path(t *testing.T) {
+	is := is.New(t)
+
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "shop", Title: "Shop"})
+	m.AddContainer(&Container{ID: "c1", Label: "shop-api", System: "shop"})
+	m.AddComponent(&Component{ID: "m1", Label: "orders", Container: "shop-api"})
+	m.PostProcess()
+	m.Deploy(&Container{ID: "c2", Label: "orders-api"}, []string{"aws-prod", "eks-cluster"}, nil)
+
+	is.Equal(m.D2Path("s1"), "s1")
+	is.Equal(m.D2Path("c1"), "s1.c1")
+	is.Equal(m.D2Path("m1"), "s1.c1.m1")
+	is.Equal(m.D2Path("c2"), m.DeploymentNodes[0].ID+"."+m.DeploymentNodes[0].Nodes[0].ID+".c2")
+	is.Equal(m.D2Path("unknown"), "unknown")
+}
@@ -0,0 +1,142 @@
+package c4
+
+import (
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// DOT_TPL_C4 renders a model as Graphviz DOT graph, with boundaries as
+// clusters.
+const DOT_TPL_C4 = `
+{{- define "dotContainer" }}
+{{- if .Components }}
+  subgraph {{ quote (print "cluster_" .ID) }} {
+    label={{ quote (label .Title (stereotype "Container" .Label) "") }}
+    style="dashed,rounded"
+    color="#444444"
+    fontcolor="#444444"
+    {{ quote .ID }} [shape=point, style=invis, width=0]
+	{{- range .Components }}
+    {{ quote .ID }} [label={{ quote (label .Title (stereotype "Component" .Technology) .Description) }}, shape={{ if .IsDatabase }}cylinder{{ else }}box{{ end }}, fillcolor={{ quote (elementColor "component" .Tags) }}, fontcolor="black"]
+	{{- end }}
+  }
+{{- else if .IsDatabase }}
+  {{ quote .ID }} [label={{ quote (label .Title (stereotype "Container" .Label) .Description) }}, shape=cylinder, fillcolor={{ quote (elementColor "database" .Tags) }}]
+{{- else }}
+  {{ quote .ID }} [label={{ quote (label .Title (stereotype "Container" .Label) .Description) }}, fillcolor={{ quote (elementColor "container" .Tags) }}]
+{{- end }}
+{{- end }}
+{{- define "dotDeploymentNode" }}
+  subgraph {{ quote (print "cluster_" .ID) }} {
+    label={{ quote (label .Title (stereotype "Deployment Node" .Technology) "") }}
+    style="rounded"
+    color="#444444"
+    fontcolor="#444444"
+	{{- range .Nodes }}
+	{{- template "dotDeploymentNode" . }}
+	{{- end }}
+	{{- range .Containers }}
+	{{- template "dotContainer" . }}
+	{{- end }}
+  }
+{{- end -}}
+digraph "c4" {
+  graph [rankdir=TB, fontname="Arial", fontsize=12, nodesep=0.8, ranksep=1]
+  node [shape=box, style="rounded,filled", fontname="Arial", fontsize=11, fontcolor="white", color="#00000033", margin=0.2]
+  edge [fontname="Arial", fontsize=10, style=dashed, color="#707070", fontcolor="#404040"]
+
+{{- range .Persons }}
+  {{ quote .ID }} [label={{ quote (label .Title (stereotype "Person" "") .Description) }}, shape=box, style="rounded,filled,bold", fillcolor={{ quote (elementColor "person" .Tags) }}]
+{{- end }}
+
+{{- range .ExternalSystems }}
+  {{ quote .ID }} [label={{ quote (label .Title (stereotype "External System" "") .Description) }}, fillcolor={{ quote (elementColor "external" .Tags) }}]
+{{- end }}
+
+{{- range .Systems }}
+{{- if and (not .Containers) (not .APIs) }}
+  {{ quote .ID }} [label={{ quote (label .Title (stereotype "Software System" "") .Description) }}, fillcolor={{ quote (elementColor "system" .Tags) }}]
+{{- else }}
+  subgraph {{ quote (print "cluster_" .ID) }} {
+    label={{ quote (label .Title (stereotype "System" "") "") }}
+    style="dashed,rounded"
+    color="#444444"
+    fontcolor="#444444"
+    {{ quote .ID }} [shape=point, style=invis, width=0]
+	{{- range .Containers }}
+	{{- template "dotContainer" . }}
+	{{- end }}
+	{{- range .APIs }}
+    {{ quote .ID }} [label={{ quote (label .Title (stereotype "API" .Type) .Description) }}, shape=octagon, style=filled, fillcolor={{ quote (elementColor "api" .Tags) }}]
+	{{- end }}
+  }
+{{- end }}
+{{- end }}
+
+{{- range .StandaloneAPIs }}
+  {{ quote .ID }} [label={{ quote (label .Title (stereotype "API" .Type) .Description) }}, shape=octagon, style=filled, fillcolor={{ quote (elementColor "api" .Tags) }}]
+{{- end }}
+
+{{- range .DeploymentNodes }}
+{{- template "dotDeploymentNode" . }}
+{{- end }}
+
+{{- range .Relations }}
+  {{ quote .SourceID }} -> {{ quote .TargetID }} [label={{ quote (label .Label (relationTechnology .Technology) "") }}, color={{ quote (relationColor .Tags) }}]
+{{- end }}
+}
+`
+
+// dotFuncs are the functions of the DOT and D2 templates.
+var dotFuncs = template.FuncMap{
+	"quote":              strconv.Quote,
+	"label":              label,
+	"stereotype":         stereotype,
+	"relationTechnology": relationTechnology,
+	"elementColor":       elementColor,
+	"relationColor":      relationColor,
+}
+
+type dotExporter struct {
+	template *template.Template
+}
+
+func newDotExporter() *dotExporter {
+	t, err := template.New("").Funcs(dotFuncs).Parse(DOT_TPL_C4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return &dotExporter{
+		template: t,
+	}
+}
+
+// ExportToDot exports the model as Graphviz DOT graph.
+func (e *dotExporter) ExportToDot(c4Model *C4DiagramModel, w io.Writer) error {
+	return e.template.Execute(w, c4Model)
+}
+
+// label joins the non empty lines of a label, separating the description
+// by an empty line.
+func label(title string, stereotype string, description string) string {
+	lines := []string{title}
+	if stereotype != "" {
+		lines = append(lines, stereotype)
+	}
+	if description != "" {
+		lines = append(lines, "", description)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// relationTechnology is the technology of a relation in brackets, if any.
+func relationTechnology(technology string) string {
+	if technology == "" {
+		return ""
+	}
+	return "[" + technology + "]"
+}
@@ -0,0 +1,65 @@
+package c4
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestExportToDot(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	e := newDotExporter()
+
+	sw := bytes.NewBufferString("")
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "customer", Title: "Customer", Type: "person"})
+	m.AddSystem(&System{ID: "s2", Label: "shop", Title: "Shop"})
+	m.AddSystem(&System{ID: "s3", Label: "psp", Title: "PSP", Type: "external"})
+	m.AddContainer(&Container{ID: "c1", Label: "shop-api", Title: "Shop API", System: "shop"})
+	m.AddContainer(&Container{ID: "c2", Label: "shop-db", Title: "Shop DB", System: "shop", Type: "database"})
+	m.AddRelation(Relation{SourceID: "s1", TargetID: "c1", Label: "orders", Technology: "REST/JSON"})
+	m.AddRelation(Relation{SourceID: "c1", TargetID: "c2", Label: "depends on", Tags: []string{"cycle"}})
+	m.PostProcess()
+
+	// Act
+	err := e.ExportToDot(m, sw)
+	dot := sw.String()
+
+	// Assert
+	is.NoErr(err)
+	is.True(strings.HasPrefix(dot, `digraph "c4" {`))
+	is.True(strings.Contains(dot, `"s1" [label="Customer\n[Person]", shape=box, style="rounded,filled,bold", fillcolor="#08427B"]`))
+	is.True(strings.Contains(dot, `"s3" [label="PSP\n[External System]", fillcolor="#999999"]`))
+	is.True(strings.Contains(dot, `subgraph "cluster_s2" {`))
+	is.True(strings.Contains(dot, `"c2" [label="Shop DB\n[Container: shop-db]", shape=cylinder, fillcolor="#438DD5"]`))
+	is.True(strings.Contains(dot, `"s1" -> "c1" [label="orders\n[REST/JSON]", color="#707070"]`))
+	is.True(strings.Contains(dot, `"c1" -> "c2" [label="depends on", color="Crimson"]`))
+}
+
+func TestExportToDotComponents(t *testing.T) {
+	is := is.New(t)
+
+	sw := bytes.NewBufferString("")
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "shop", Title: "Shop"})
+	m.AddContainer(&Container{ID: "c1", Label: "shop-api", Title: "Shop API", System: "shop"})
+	m.AddComponent(&Component{ID: "m1", Label: "orders", Title: "Orders", Container: "shop-api", Technology: "go"})
+	m.PostProcess()
+
+	err := newDotExporter().ExportToDot(m, sw)
+	dot := sw.String()
+
+	is.NoErr(err)
+	is.True(strings.Contains(dot, `subgraph "cluster_c1" {`))
+	is.True(strings.Contains(dot, `"m1" [label="Orders\n[Component: go]", shape=box, fillcolor="#85BBF0", fontcolor="black"]`))
+}
+
+func TestLabel(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(label("Shop", "[System]", "sells"), "Shop\n[System]\n\nsells")
+	is.Equal(label("uses", "", ""), "uses")
+}
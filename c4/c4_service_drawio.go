@@ -0,0 +1,165 @@
+package c4
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"strings"
+	"text/template"
+)
+
+// DRAWIO_TPL_C4 renders the cells of a laid out model as draw.io file.
+const DRAWIO_TPL_C4 = `<mxfile host="c4stage">
+  <diagram id="c4" name="C4">
+    <mxGraphModel grid="1" gridSize="10" guides="1" tooltips="1" connect="1" arrows="1" fold="1" page="1" pageScale="1" pageWidth="{{ .Width }}" pageHeight="{{ .Height }}" math="0" shadow="0">
+      <root>
+        <mxCell id="0"/>
+        <mxCell id="1" parent="0"/>
+{{- range .Cells }}
+{{- if .Edge }}
+        <mxCell id="{{ xml .ID }}" value="{{ xml .Value }}" style="{{ .Style }}" edge="1" parent="{{ xml .Parent }}" source="{{ xml .Source }}" target="{{ xml .Target }}">
+          <mxGeometry relative="1" as="geometry"/>
+        </mxCell>
+{{- else }}
+        <mxCell id="{{ xml .ID }}" value="{{ xml .Value }}" style="{{ .Style }}" vertex="1" parent="{{ xml .Parent }}">
+          <mxGeometry x="{{ .X }}" y="{{ .Y }}" width="{{ .Width }}" height="{{ .Height }}" as="geometry"/>
+        </mxCell>
+{{- end }}
+{{- end }}
+      </root>
+    </mxGraphModel>
+  </diagram>
+</mxfile>
+`
+
+// drawioStyles are the styles of the elements by kind, as in the C4 shape
+// library of draw.io.
+var drawioStyles = map[string]string{
+	"person":    "html=1;fontSize=11;dashed=0;whiteSpace=wrap;fillColor=#083F75;strokeColor=#06315C;fontColor=#ffffff;shape=mxgraph.c4.person2;align=center;metaEdit=1;",
+	"system":    "rounded=1;whiteSpace=wrap;html=1;labelBackgroundColor=none;fillColor=#1061B0;fontColor=#ffffff;align=center;arcSize=10;strokeColor=#0D5091;metaEdit=1;",
+	"external":  "rounded=1;whiteSpace=wrap;html=1;labelBackgroundColor=none;fillColor=#8C8496;fontColor=#ffffff;align=center;arcSize=10;strokeColor=#736782;metaEdit=1;",
+	"container": "rounded=1;whiteSpace=wrap;html=1;labelBackgroundColor=none;fillColor=#23A2D9;fontColor=#ffffff;align=center;arcSize=10;strokeColor=#0E7DAD;metaEdit=1;",
+	"database":  "shape=cylinder3;size=15;whiteSpace=wrap;html=1;boundedLbl=1;rounded=0;labelBackgroundColor=none;fillColor=#23A2D9;fontSize=12;fontColor=#ffffff;align=center;strokeColor=#0E7DAD;metaEdit=1;",
+	"component": "rounded=1;whiteSpace=wrap;html=1;labelBackgroundColor=none;fillColor=#63BEF2;fontColor=#ffffff;align=center;arcSize=6;strokeColor=#2086C9;metaEdit=1;",
+	"api":       "shape=mxgraph.basic.octagon2;dx=15;whiteSpace=wrap;html=1;labelBackgroundColor=none;fillColor=#708090;fontColor=#ffffff;align=center;strokeColor=#4F5B66;metaEdit=1;",
+}
+
+const (
+	drawioBoundaryStyle = "rounded=1;fontSize=11;whiteSpace=wrap;html=1;dashed=1;arcSize=20;fillColor=none;strokeColor=#666666;fontColor=#333333;labelBackgroundColor=none;align=left;verticalAlign=top;spacing=10;dashPattern=8 4;metaEdit=1;container=1;collapsible=0;recursiveResize=0;absoluteArcSize=1;"
+	drawioEdgeStyle     = "endArrow=blockThin;html=1;fontSize=10;fontColor=#404040;strokeWidth=1;endFill=1;strokeColor=#828282;elbow=vertical;metaEdit=1;endSize=14;startSize=14;rounded=0;"
+)
+
+// drawioCell is a vertex or an edge of a draw.io diagram, placed relative
+// to its parent.
+type drawioCell struct {
+	ID     string
+	Value  string
+	Style  string
+	Parent string
+	Edge   bool
+	Source string
+	Target string
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+type drawioDiagram struct {
+	Width  float64
+	Height float64
+	Cells  []drawioCell
+}
+
+type drawioExporter struct {
+	template *template.Template
+}
+
+func newDrawioExporter() *drawioExporter {
+	t, err := template.New("").Funcs(template.FuncMap{
+		"xml": xmlEscape,
+	}).Parse(DRAWIO_TPL_C4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return &drawioExporter{
+		template: t,
+	}
+}
+
+// ExportToDrawio lays out the model and exports it as draw.io diagram.
+func (e *drawioExporter) ExportToDrawio(c4Model *C4DiagramModel, w io.Writer) error {
+	return e.template.Execute(w, drawioDiagramOf(LayoutOf(c4Model)))
+}
+
+// drawioDiagramOf converts the layout to cells, the boundaries being parents
+// of the elements within.
+func drawioDiagramOf(layout *Layout) drawioDiagram {
+	diagram := drawioDiagram{
+		Width:  layout.Width,
+		Height: layout.Height,
+	}
+
+	boundaries := make(map[string]*LayoutBoundary)
+	for _, boundary := range layout.Boundaries {
+		boundaries[boundary.ID] = boundary
+	}
+	cell := func(id string, parent string, box LayoutBox) drawioCell {
+		c := drawioCell{ID: id, Parent: "1", X: box.X, Y: box.Y, Width: box.Width, Height: box.Height}
+		if p, ok := boundaries[parent]; ok {
+			c.Parent = p.ID
+			c.X -= p.X
+			c.Y -= p.Y
+		}
+		return c
+	}
+
+	for _, boundary := range layout.Boundaries {
+		c := cell(boundary.ID, boundary.Parent, boundary.LayoutBox)
+		c.Value = fmt.Sprintf("<b>%v</b><div>%v</div>", html.EscapeString(boundary.Title), html.EscapeString(boundary.Stereotype()))
+		c.Style = drawioBoundaryStyle
+		if color := tagColor(tagElementColors, boundary.Tags); color != "" {
+			c.Style += "strokeColor=" + color + ";"
+		}
+		diagram.Cells = append(diagram.Cells, c)
+	}
+
+	for _, node := range layout.Nodes {
+		c := cell(node.ID, node.Parent, node.LayoutBox)
+		c.Value = fmt.Sprintf("<b>%v</b><div>%v</div>", html.EscapeString(node.Title), html.EscapeString(node.Stereotype()))
+		if node.Description != "" {
+			c.Value += "<br><div>" + html.EscapeString(node.Description) + "</div>"
+		}
+		c.Style = drawioStyles[node.Kind]
+		if color := tagColor(tagElementColors, node.Tags); color != "" {
+			c.Style += "fillColor=" + color + ";"
+		}
+		diagram.Cells = append(diagram.Cells, c)
+	}
+
+	for i, edge := range layout.Edges {
+		value := html.EscapeString(edge.Label)
+		if edge.Technology != "" {
+			value += "<div>[" + html.EscapeString(edge.Technology) + "]</div>"
+		}
+
+		style := drawioEdgeStyle
+		if color := tagColor(tagRelationColors, edge.Tags); color != "" {
+			style = strings.Replace(style, "strokeColor=#828282;", "strokeColor="+color+";", 1)
+		}
+
+		diagram.Cells = append(diagram.Cells, drawioCell{
+			ID:     fmt.Sprintf("rel%v", i+1),
+			Value:  value,
+			Style:  style,
+			Parent: "1",
+			Edge:   true,
+			Source: edge.SourceID,
+			Target: edge.TargetID,
+		})
+	}
+
+	return diagram
+}
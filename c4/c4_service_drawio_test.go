@@ -0,0 +1,59 @@
+package c4
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestExportToDrawio(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	e := newDrawioExporter()
+
+	sw := bytes.NewBufferString("")
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "customer", Title: "Customer", Type: "person"})
+	m.AddSystem(&System{ID: "s2", Label: "shop", Title: "Shop"})
+	m.AddContainer(&Container{ID: "c1", Label: "shop-api", Title: "Shop <API>", System: "shop", Technology: "go"})
+	m.AddRelation(Relation{SourceID: "s1", TargetID: "c1", Label: "orders", Technology: "REST/JSON", Tags: []string{"path"}})
+	m.PostProcess()
+
+	// Act
+	err := e.ExportToDrawio(m, sw)
+	drawio := sw.String()
+
+	// Assert
+	is.NoErr(err)
+	is.NoErr(xml.Unmarshal(sw.Bytes(), new(struct{})))
+	is.True(strings.HasPrefix(drawio, "<mxfile"))
+	is.True(strings.Contains(drawio, `<mxCell id="s2" value="&lt;b&gt;Shop&lt;/b&gt;&lt;div&gt;[System]&lt;/div&gt;" style="rounded=1;fontSize=11;`))
+	is.True(strings.Contains(drawio, `&lt;b&gt;Shop &amp;lt;API&amp;gt;&lt;/b&gt;&lt;div&gt;[Container: go]&lt;/div&gt;`))
+	is.True(strings.Contains(drawio, `shape=mxgraph.c4.person2`))
+	is.True(strings.Contains(drawio, `edge="1" parent="1" source="s1" target="c1"`))
+	is.True(strings.Contains(drawio, `strokeColor=Crimson;`))
+}
+
+func TestDrawioDiagramOfPlacesChildrenRelativeToParent(t *testing.T) {
+	is := is.New(t)
+
+	layout := &Layout{
+		Boundaries: []*LayoutBoundary{{ID: "s1", LayoutBox: LayoutBox{X: 40, Y: 40, Width: 300, Height: 200}}},
+		Nodes: []*LayoutNode{
+			{ID: "c1", Parent: "s1", Kind: "container", LayoutBox: LayoutBox{X: 60, Y: 90, Width: 220, Height: 130}},
+			{ID: "s2", Kind: "system", LayoutBox: LayoutBox{X: 400, Y: 90, Width: 220, Height: 130}},
+		},
+	}
+
+	diagram := drawioDiagramOf(layout)
+
+	is.Equal(len(diagram.Cells), 3)
+	is.Equal(diagram.Cells[1].Parent, "s1")
+	is.Equal(diagram.Cells[1].X, 20.0)
+	is.Equal(diagram.Cells[1].Y, 50.0)
+	is.Equal(diagram.Cells[2].Parent, "1")
+	is.Equal(diagram.Cells[2].X, 400.0)
+}
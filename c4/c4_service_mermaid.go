@@ -0,0 +1,155 @@
+package c4
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"text/template"
+)
+
+// MERMAID_TPL_C4 renders a model as Mermaid C4 diagram, the type of diagram
+// given by the elements of the model.
+const MERMAID_TPL_C4 = `
+{{- define "mermaidContainer" }}
+	{{- if .Components }}
+    Container_Boundary({{.ID}}, "{{.Title}}") {
+		{{- range .Components}}
+			{{- if .IsDatabase }}
+      ComponentDb({{.ID}}, "{{.Title}}", "{{.Technology}}", "{{.Description}}")
+			{{- else }}
+      Component({{.ID}}, "{{.Title}}", "{{.Technology}}", "{{.Description}}")
+			{{- end }}
+		{{- end}}
+    }
+	{{- else if .IsDatabase }}
+    ContainerDb({{.ID}}, "{{.Title}}", "{{.Label}}", "{{.Description}}")
+	{{- else if .IsPerson }}
+	{{- else }}
+    Container({{.ID}}, "{{.Title}}", "{{.Label}}", "{{.Description}}")
+	{{- end }}
+{{- end }}
+{{- define "mermaidDeploymentNode" }}
+    Deployment_Node({{.ID}}, "{{.Title}}", "{{.Technology}}", "{{.Description}}") {
+	{{- range .Nodes}}
+	{{- template "mermaidDeploymentNode" . }}
+	{{- end}}
+	{{- range .Containers}}
+	{{- template "mermaidContainer" . }}
+	{{- end}}
+    }
+{{- end -}}
+{{ .MermaidDiagramType }}
+
+{{- range .Persons}}
+  Person({{.ID}}, "{{.Title}}", "{{.Description}}")
+{{- end}}
+
+{{- range .ExternalSystems}}
+  System_Ext({{.ID}}, "{{.Title}}", "{{.Description}}")
+{{- end}}
+
+{{- range .Systems}}
+{{- if and (not .Containers) (not .APIs) }}
+  System({{.ID}}, "{{.Title}}", "{{.Description}}")
+{{- else }}
+  System_Boundary({{.ID}}, "{{.Title}}") {
+	{{- range .Containers}}
+	{{- template "mermaidContainer" . }}
+	{{- end}}
+	{{- range .APIs}}
+    Container({{.ID}}, "{{.Title}}", "{{.Type}}", "{{.Description}}")
+	{{- end}}
+  }
+{{- end }}
+{{- end}}
+
+{{- range .StandaloneAPIs}}
+  Container({{.ID}}, "{{.Title}}", "{{.Type}}", "{{.Description}}")
+{{- end}}
+
+{{- range .DeploymentNodes}}
+{{- template "mermaidDeploymentNode" . }}
+{{- end}}
+
+{{- range .Relations}}
+  Rel({{.SourceID}}, {{.TargetID}}, "{{.Label}}", "{{.Technology}}")
+{{- end}}
+
+{{- range .MermaidStyles}}
+  {{.}}
+{{- end}}
+`
+
+type mermaidExporter struct {
+	template *template.Template
+}
+
+func newMermaidExporter() *mermaidExporter {
+	t, err := template.New("").Parse(MERMAID_TPL_C4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return &mermaidExporter{
+		template: t,
+	}
+}
+
+// ExportToMermaid exports the model as Mermaid C4 diagram.
+func (e *mermaidExporter) ExportToMermaid(c4Model *C4DiagramModel, w io.Writer) error {
+	return e.template.Execute(w, c4Model)
+}
+
+// ExportToMermaid exports the model as Mermaid C4 diagram for the Mermaid
+// source of diagrams queried with GraphQL.
+func ExportToMermaid(c4Model *C4DiagramModel, w io.Writer) error {
+	return newMermaidExporter().ExportToMermaid(c4Model, w)
+}
+
+// MermaidDiagramType returns the type of Mermaid C4 diagram showing all
+// elements of the model.
+func (c4Model *C4DiagramModel) MermaidDiagramType() string {
+	switch {
+	case len(c4Model.DeploymentNodes) > 0:
+		return "C4Deployment"
+	case len(c4Model.Components) > 0:
+		return "C4Component"
+	case len(c4Model.Containers) > 0 || len(c4Model.APIs) > 0:
+		return "C4Container"
+	default:
+		return "C4Context"
+	}
+}
+
+// MermaidStyles returns the style updates colouring the tagged elements and
+// relations of the model by their first tag with a color.
+func (c4Model *C4DiagramModel) MermaidStyles() []string {
+	var styles []string
+
+	elementStyle := func(id string, tags []string) {
+		if color := tagColor(tagElementColors, tags); color != "" {
+			styles = append(styles, fmt.Sprintf(`UpdateElementStyle(%v, $bgColor="%v")`, id, color))
+		}
+	}
+	for _, system := range c4Model.allSystems() {
+		elementStyle(system.ID, system.Tags)
+	}
+	for _, container := range c4Model.Containers {
+		elementStyle(container.ID, container.Tags)
+	}
+	for _, component := range c4Model.Components {
+		elementStyle(component.ID, component.Tags)
+	}
+	for _, api := range c4Model.APIs {
+		elementStyle(api.ID, append(slices.Clone(api.Tags), "api"))
+	}
+
+	for _, relation := range c4Model.Relations {
+		if color := tagColor(tagRelationColors, relation.Tags); color != "" {
+			styles = append(styles, fmt.Sprintf(`UpdateRelStyle(%v, %v, $textColor="%v", $lineColor="%v")`, relation.SourceID, relation.TargetID, color, color))
+		}
+	}
+
+	return styles
+}
@@ -0,0 +1,71 @@
+package c4
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestExportToMermaidContext(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	e := newMermaidExporter()
+
+	sw := bytes.NewBufferString("")
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "customer", Title: "Customer", Type: "person"})
+	m.AddSystem(&System{ID: "s2", Label: "shop", Title: "Shop", Tags: []string{"deprecated"}})
+	m.AddSystem(&System{ID: "s3", Label: "psp", Title: "PSP", Type: "external"})
+	m.AddRelation(Relation{SourceID: "s1", TargetID: "s2", Label: "uses"})
+	m.AddRelation(Relation{SourceID: "s2", TargetID: "s3", Label: "uses payments", Technology: "REST/JSON", Tags: []string{"cycle"}})
+
+	// Act
+	err := e.ExportToMermaid(m, sw)
+	mermaid := sw.String()
+
+	// Assert
+	is.NoErr(err)
+	is.True(strings.HasPrefix(mermaid, "C4Context\n"))
+	is.True(strings.Contains(mermaid, `  Person(s1, "Customer", "")`))
+	is.True(strings.Contains(mermaid, `  System(s2, "Shop", "")`))
+	is.True(strings.Contains(mermaid, `  System_Ext(s3, "PSP", "")`))
+	is.True(strings.Contains(mermaid, `  Rel(s2, s3, "uses payments", "REST/JSON")`))
+	is.True(strings.Contains(mermaid, `  UpdateElementStyle(s2, $bgColor="DarkCyan")`))
+	is.True(strings.Contains(mermaid, `  UpdateRelStyle(s2, s3, $textColor="Crimson", $lineColor="Crimson")`))
+}
+
+func TestExportToMermaidContainer(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	e := newMermaidExporter()
+
+	sw := bytes.NewBufferString("")
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "shop", Title: "Shop"})
+	m.AddContainer(&Container{ID: "c1", Label: "shop-api", Title: "Shop API", System: "shop"})
+	m.AddContainer(&Container{ID: "c2", Label: "shop-db", Title: "Shop DB", System: "shop", Type: "database"})
+	m.AddRelation(Relation{SourceID: "c1", TargetID: "c2", Label: "depends on"})
+	m.PostProcess()
+
+	// Act
+	err := e.ExportToMermaid(m, sw)
+	mermaid := sw.String()
+
+	// Assert
+	is.NoErr(err)
+	is.True(strings.HasPrefix(mermaid, "C4Container\n"))
+	is.True(strings.Contains(mermaid, `  System_Boundary(s1, "Shop") {`))
+	is.True(strings.Contains(mermaid, `    Container(c1, "Shop API", "shop-api", "")`))
+	is.True(strings.Contains(mermaid, `    ContainerDb(c2, "Shop DB", "shop-db", "")`))
+	is.True(strings.Contains(mermaid, `  Rel(c1, c2, "depends on", "")`))
+}
+
+func TestMermaidDiagramType(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal((&C4DiagramModel{}).MermaidDiagramType(), "C4Context")
+	is.Equal((&C4DiagramModel{Components: []*Component{{}}}).MermaidDiagramType(), "C4Component")
+	is.Equal((&C4DiagramModel{DeploymentNodes: []*DeploymentNode{{}}}).MermaidDiagramType(), "C4Deployment")
+}
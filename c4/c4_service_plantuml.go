@@ -6,23 +6,52 @@ import (
 	"text/template"
 )
 
+// PLANT_UML_TPL_TAGS defines the styles of all whitelisted tags.
+const PLANT_UML_TPL_TAGS = `
+{{- define "tags" }}
+AddElementTag("experimental", $bgColor="DeepSkyBlue")
+AddElementTag("deprecated", $bgColor="DarkCyan")
+AddElementTag("added", $bgColor="Green")
+AddElementTag("removed", $bgColor="Red")
+AddElementTag("changed", $bgColor="Orange")
+AddElementTag("cycle", $bgColor="Crimson")
+AddElementTag("hop0", $bgColor="Crimson")
+AddElementTag("hop1", $bgColor="OrangeRed")
+AddElementTag("hop2", $bgColor="DarkOrange")
+AddElementTag("hop3", $bgColor="Goldenrod")
+AddElementTag("path", $bgColor="Crimson")
+AddElementTag("api", $shape=EightSidedShape(), $bgColor="SlateGray", $legendText="api")
+AddElementTag("focus", $bgColor="Crimson", $borderColor="DarkRed", $shadowing="true")
+AddBoundaryTag("added", $fontColor="Green", $borderColor="Green")
+AddBoundaryTag("removed", $fontColor="Red", $borderColor="Red")
+AddBoundaryTag("changed", $fontColor="Orange", $borderColor="Orange")
+AddRelTag("added", $textColor="Green", $lineColor="Green")
+AddRelTag("removed", $textColor="Red", $lineColor="Red")
+AddRelTag("changed", $textColor="Orange", $lineColor="Orange")
+AddRelTag("cycle", $textColor="Crimson", $lineColor="Crimson")
+AddRelTag("path", $textColor="Crimson", $lineColor="Crimson", $lineStyle=BoldLine())
+AddRelTag("inbound", $textColor="SteelBlue", $lineColor="SteelBlue")
+AddRelTag("outbound", $textColor="DarkOrange", $lineColor="DarkOrange")
+AddRelTag("violation", $textColor="Red", $lineColor="Red", $lineStyle=BoldLine())
+{{- end }}
+`
+
 const PLANT_UML_TPL_C4_CONTEXT = `
 @startuml
 !include <C4/C4_Context>
 
 SHOW_PERSON_PORTRAIT()
 
-AddElementTag("experimental", $bgColor="DeepSkyBlue")
-AddElementTag("deprecated", $bgColor="DarkCyan")
+{{- template "tags" }}
 
 ' Persons
 {{- range .Persons}}
-Person({{.ID}}, "{{.Title}}", "{{.Description}}")
+Person({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
 {{- end}}
 
 ' External Systems
 {{- range .ExternalSystems}}
-System_Ext({{.ID}}, "{{.Title}}", "{{.Description}}")
+System_Ext({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
 {{- end}}
 
 ' Systems
@@ -32,7 +61,7 @@ System({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
 
 ' Relations
 {{- range .Relations}}
-Rel({{.SourceID}}, {{.TargetID}}, "{{.Label}}")
+Rel({{.SourceID}}, {{.TargetID}}, "{{.Label}}", "{{.Technology}}", $tags="{{.AsTags}}")
 {{- end}}
 
 SHOW_LEGEND()
@@ -56,22 +85,24 @@ SHOW_PERSON_PORTRAIT()
 
 scale {{ .ScaleFormatted }}
 
-AddElementTag("experimental", $bgColor="DeepSkyBlue")
-AddElementTag("deprecated", $bgColor="DarkCyan")
+{{- template "tags" }}
 
 ' Persons
 {{- range .Persons}}
-Person({{.ID}}, "{{.Title}}", "{{.Description}}")
+Person({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
 {{- end}}
 
 ' External Systems
 {{- range .ExternalSystems}}
-System_Ext({{.ID}}, "{{.Title}}", "{{.Description}}")
+System_Ext({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
 {{- end}}
 
 ' Systems
 {{- range .Systems}}
-System_Boundary({{.ID}}, "{{.Title}}", "{{.Description}}") {
+{{- if and (not .Containers) (not .APIs) }}
+System({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
+{{- else }}
+System_Boundary({{.ID}}, "{{.Title}}", $tags="{{.AsTags}}") {
 	{{- range .Containers}}
 		{{- if .IsDatabase }}
 		ContainerDb({{.ID}}, "{{.Title}}", "{{.Label}}", "{{.Description}}", $tags="{{.AsTags}}", $sprite="{{.Sprite}}")
@@ -80,12 +111,115 @@ System_Boundary({{.ID}}, "{{.Title}}", "{{.Description}}") {
 		Container({{.ID}}, "{{.Title}}", "{{.Label}}", "{{.Description}}", $tags="{{.AsTags}}", $sprite="{{.Sprite}}")
 		{{- end }}
 	{{- end}}
+	{{- range .APIs}}
+		Container({{.ID}}, "{{.Title}}", "{{.Type}}", "{{.Description}}", $tags="{{.AsTags}}")
+	{{- end}}
 }
+{{- end }}
+{{- end}}
+
+' APIs
+{{- range .StandaloneAPIs}}
+Container({{.ID}}, "{{.Title}}", "{{.Type}}", "{{.Description}}", $tags="{{.AsTags}}")
+{{- end}}
+
+' Relations
+{{- range .Relations}}
+Rel({{.SourceID}}, {{.TargetID}}, "{{.Label}}", "{{.Technology}}", $tags="{{.AsTags}}")
+{{- end}}
+
+SHOW_LEGEND()
+
+@enduml
+`
+
+const PLANT_UML_TPL_C4_COMPONENT = `
+@startuml
+!include <C4/C4_Component>
+
+SHOW_PERSON_PORTRAIT()
+
+scale {{ .ScaleFormatted }}
+
+{{- template "tags" }}
+
+' Persons
+{{- range .Persons}}
+Person({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
+{{- end}}
+
+' External Systems
+{{- range .ExternalSystems}}
+System_Ext({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
+{{- end}}
+
+' Systems
+{{- range .Systems}}
+{{- if not .Containers }}
+System({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
+{{- else }}
+System_Boundary({{.ID}}, "{{.Title}}", $tags="{{.AsTags}}") {
+	{{- range .Containers}}
+		{{- if .Components }}
+		Container_Boundary({{.ID}}, "{{.Title}}", $tags="{{.AsTags}}") {
+			{{- range .Components}}
+				{{- if .IsDatabase }}
+				ComponentDb({{.ID}}, "{{.Title}}", "{{.Technology}}", "{{.Description}}", $tags="{{.AsTags}}")
+				{{- else }}
+				Component({{.ID}}, "{{.Title}}", "{{.Technology}}", "{{.Description}}", $tags="{{.AsTags}}")
+				{{- end }}
+			{{- end}}
+		}
+		{{- else if .IsDatabase }}
+		ContainerDb({{.ID}}, "{{.Title}}", "{{.Label}}", "{{.Description}}", $tags="{{.AsTags}}")
+		{{- else }}
+		Container({{.ID}}, "{{.Title}}", "{{.Label}}", "{{.Description}}", $tags="{{.AsTags}}")
+		{{- end }}
+	{{- end}}
+}
+{{- end }}
+{{- end}}
+
+' Relations
+{{- range .Relations}}
+Rel({{.SourceID}}, {{.TargetID}}, "{{.Label}}", "{{.Technology}}", $tags="{{.AsTags}}")
+{{- end}}
+
+SHOW_LEGEND()
+
+@enduml
+`
+
+const PLANT_UML_TPL_C4_DEPLOYMENT = `
+{{- define "deploymentNode" }}
+Deployment_Node({{.ID}}, "{{.Title}}", "{{.Technology}}", "{{.Description}}", $tags="{{.AsTags}}") {
+	{{- range .Nodes}}
+	{{- template "deploymentNode" . }}
+	{{- end}}
+	{{- range .Containers}}
+		{{- if .IsDatabase }}
+		ContainerDb({{.ID}}, "{{.Title}}", "{{.Label}}", "{{.Description}}", $tags="{{.AsTags}}")
+		{{- else }}
+		Container({{.ID}}, "{{.Title}}", "{{.Label}}", "{{.Description}}", $tags="{{.AsTags}}")
+		{{- end }}
+	{{- end}}
+}
+{{- end }}
+@startuml
+!include <C4/C4_Deployment>
+
+scale {{ .ScaleFormatted }}
+
+{{- template "tags" }}
+
+' Deployment Nodes
+{{- range .DeploymentNodes}}
+{{- template "deploymentNode" . }}
 {{- end}}
 
 ' Relations
 {{- range .Relations}}
-Rel({{.SourceID}}, {{.TargetID}}, "{{.Label}}")
+Rel({{.SourceID}}, {{.TargetID}}, "{{.Label}}", "{{.Technology}}", $tags="{{.AsTags}}")
 {{- end}}
 
 SHOW_LEGEND()
@@ -93,33 +227,138 @@ SHOW_LEGEND()
 @enduml
 `
 
+const PLANT_UML_TPL_C4_DYNAMIC = `
+@startuml
+!include <C4/C4_Dynamic>
+
+SHOW_PERSON_PORTRAIT()
+
+scale {{ .ScaleFormatted }}
+
+{{- template "tags" }}
+
+' Persons
+{{- range .Persons}}
+Person({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
+{{- end}}
+
+' External Systems
+{{- range .ExternalSystems}}
+System_Ext({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
+{{- end}}
+
+' Systems
+{{- range .Systems}}
+{{- if not .Containers }}
+System({{.ID}}, "{{.Title}}", "{{.Description}}", $tags="{{.AsTags}}")
+{{- else }}
+System_Boundary({{.ID}}, "{{.Title}}", $tags="{{.AsTags}}") {
+	{{- range .Containers}}
+		{{- if .IsDatabase }}
+		ContainerDb({{.ID}}, "{{.Title}}", "{{.Label}}", "{{.Description}}", $tags="{{.AsTags}}")
+		{{- else }}
+		Container({{.ID}}, "{{.Title}}", "{{.Label}}", "{{.Description}}", $tags="{{.AsTags}}")
+		{{- end }}
+	{{- end}}
+}
+{{- end }}
+{{- end}}
+
+' Steps
+{{- range .Relations}}
+Rel({{.SourceID}}, {{.TargetID}}, "{{.Label}}", "{{.Technology}}")
+{{- end}}
+
+SHOW_LEGEND()
+
+@enduml
+`
+
+const PLANT_UML_TPL_SEQUENCE = `
+@startuml
+autonumber
+
+scale {{ .ScaleFormatted }}
+
+' Participants
+{{- range .Participants}}
+{{.Kind}} "{{.Title}}" as {{.ID}}
+{{- end}}
+
+' Steps
+{{- range .SequenceSteps}}
+{{.SourceID}} -> {{.TargetID}} : {{.Label}}{{ if .Technology }} [{{.Technology}}]{{ end }}
+{{- end}}
+
+@enduml
+`
+
 type plantUMLExporter struct {
-	templateContext   *template.Template
-	templateContainer *template.Template
+	templateContext    *template.Template
+	templateContainer  *template.Template
+	templateComponent  *template.Template
+	templateDeployment *template.Template
+	templateDynamic    *template.Template
+	templateSequence   *template.Template
 }
 
 func newPlantUMLExporter() *plantUMLExporter {
 	e := &plantUMLExporter{}
 
-	t, err := template.New("").Parse(PLANT_UML_TPL_C4_CONTEXT)
+	e.templateContext = parsePlantUMLTemplate(PLANT_UML_TPL_C4_CONTEXT)
+	e.templateContainer = parsePlantUMLTemplate(PLANT_UML_TPL_C4_LANDSCAPE_CONTAINER)
+	e.templateComponent = parsePlantUMLTemplate(PLANT_UML_TPL_C4_COMPONENT)
+	e.templateDeployment = parsePlantUMLTemplate(PLANT_UML_TPL_C4_DEPLOYMENT)
+	e.templateDynamic = parsePlantUMLTemplate(PLANT_UML_TPL_C4_DYNAMIC)
+	e.templateSequence = parsePlantUMLTemplate(PLANT_UML_TPL_SEQUENCE)
+
+	return e
+}
+
+func parsePlantUMLTemplate(text string) *template.Template {
+	t, err := template.New("").Parse(PLANT_UML_TPL_TAGS)
 	if err != nil {
 		log.Fatal(err)
 	}
-	e.templateContext = t
 
-	t, err = template.New("").Parse(PLANT_UML_TPL_C4_LANDSCAPE_CONTAINER)
+	t, err = t.Parse(text)
 	if err != nil {
 		log.Fatal(err)
 	}
-	e.templateContainer = t
 
-	return e
+	return t
 }
 
 func (e *plantUMLExporter) ExportToPlantUMLContainer(c4Model *C4DiagramModel, w io.Writer) error {
 	return e.templateContainer.Execute(w, c4Model)
 }
 
+func (e *plantUMLExporter) ExportToPlantUMLComponent(c4Model *C4DiagramModel, w io.Writer) error {
+	return e.templateComponent.Execute(w, c4Model)
+}
+
+func (e *plantUMLExporter) ExportToPlantUMLDeployment(c4Model *C4DiagramModel, w io.Writer) error {
+	return e.templateDeployment.Execute(w, c4Model)
+}
+
+func (e *plantUMLExporter) ExportToPlantUMLDynamic(c4Model *C4DiagramModel, w io.Writer) error {
+	return e.templateDynamic.Execute(w, c4Model)
+}
+
+func (e *plantUMLExporter) ExportToPlantUMLSequence(c4Model *C4DiagramModel, w io.Writer) error {
+	return e.templateSequence.Execute(w, c4Model)
+}
+
 func (e *plantUMLExporter) ExportToPlantUMLContext(c4Model *C4DiagramModel, w io.Writer) error {
 	return e.templateContext.Execute(w, c4Model)
 }
+
+// ExportToPlantUML exports the model as PlantUML container diagram or, if
+// containers is false, as PlantUML context diagram.
+func ExportToPlantUML(c4Model *C4DiagramModel, containers bool, w io.Writer) error {
+	e := newPlantUMLExporter()
+	if containers {
+		return e.ExportToPlantUMLContainer(c4Model, w)
+	}
+	return e.ExportToPlantUMLContext(c4Model, w)
+}
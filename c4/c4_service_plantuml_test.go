@@ -31,3 +31,60 @@ func TestExportToPlantUMLContext(t *testing.T) {
 	is.NoErr(err)
 	is.True(strings.Contains(puml, "my-system"))
 }
+
+func TestExportToPlantUMLComponent(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	e := newPlantUMLExporter()
+
+	sw := bytes.NewBufferString("")
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "shop", Title: "Shop"})
+	m.AddContainer(&Container{ID: "c1", Label: "shop-api", Title: "Shop API", System: "shop"})
+	m.AddContainer(&Container{ID: "c2", Label: "shop-db", Title: "Shop DB", System: "shop", Type: "database"})
+	m.AddComponent(&Component{ID: "sc1", Label: "billing", Title: "Billing", Container: "shop-api"})
+	m.AddComponent(&Component{ID: "sc2", Label: "invoicing", Title: "Invoicing", Container: "shop-api"})
+	m.AddRelation(Relation{SourceID: "sc1", TargetID: "sc2", Label: "depends on"})
+	m.AddRelation(Relation{SourceID: "sc2", TargetID: "c2", Label: "depends on"})
+	m.PostProcess()
+
+	// Act
+	err := e.ExportToPlantUMLComponent(m, sw)
+	puml := sw.String()
+
+	// Assert
+	is.NoErr(err)
+	is.True(strings.Contains(puml, `Container_Boundary(c1, "Shop API"`))
+	is.True(strings.Contains(puml, `Component(sc1, "Billing"`))
+	is.True(strings.Contains(puml, `ContainerDb(c2, "Shop DB"`))
+	is.True(strings.Contains(puml, "Rel(sc2, c2"))
+}
+
+func TestExportToPlantUMLContainerWithAPIs(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	e := newPlantUMLExporter()
+
+	sw := bytes.NewBufferString("")
+	m := &C4DiagramModel{}
+	m.AddSystem(&System{ID: "s1", Label: "orders", Title: "Orders"})
+	m.AddSystem(&System{ID: "s2", Label: "psp", Title: "PSP", Type: "external"})
+	m.AddContainer(&Container{ID: "c1", Label: "orders-service", Title: "Orders Service", System: "orders"})
+	m.AddAPI(&API{ID: "a1", Label: "orders-api", Title: "Orders API", Type: "openapi", System: "orders"})
+	m.AddAPI(&API{ID: "a2", Label: "payments", Title: "Payments", Type: "grpc", System: "psp"})
+	m.AddRelation(Relation{SourceID: "c1", TargetID: "a1", Label: "provides"})
+	m.AddRelation(Relation{SourceID: "c1", TargetID: "a2", Label: "uses"})
+	m.PostProcess()
+
+	// Act
+	err := e.ExportToPlantUMLContainer(m, sw)
+	puml := sw.String()
+
+	// Assert
+	is.NoErr(err)
+	is.True(strings.Contains(puml, `System_Boundary(s1, "Orders"`))
+	is.True(strings.Contains(puml, `Container(a1, "Orders API", "openapi", "", $tags="api")`))
+	is.True(strings.Contains(puml, `Container(a2, "Payments", "grpc", "", $tags="api")`))
+	is.Equal(len(m.StandaloneAPIs()), 1)
+	is.True(m.HasElement("a2"))
+}
@@ -0,0 +1,461 @@
+package c4
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.io/remast/c4stage/catalog"
+)
+
+// StructurizrWorkspace is a Structurizr workspace as read by Structurizr
+// Lite from `workspace.json`.
+type StructurizrWorkspace struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Model       StructurizrModel `json:"model"`
+	Views       StructurizrViews `json:"views"`
+}
+
+type StructurizrModel struct {
+	People          []*StructurizrElement `json:"people,omitempty"`
+	SoftwareSystems []*StructurizrElement `json:"softwareSystems,omitempty"`
+}
+
+// StructurizrElement is a person, software system or container.
+type StructurizrElement struct {
+	ID            string                     `json:"id"`
+	Name          string                     `json:"name"`
+	Description   string                     `json:"description,omitempty"`
+	Technology    string                     `json:"technology,omitempty"`
+	Tags          string                     `json:"tags"`
+	Location      string                     `json:"location,omitempty"`
+	Containers    []*StructurizrElement      `json:"containers,omitempty"`
+	Relationships []*StructurizrRelationship `json:"relationships,omitempty"`
+	// Identifier identifies the element in the DSL.
+	Identifier string `json:"-"`
+	// CustomTags are the tags of the element besides the default tags.
+	CustomTags []string `json:"-"`
+}
+
+type StructurizrRelationship struct {
+	ID            string `json:"id"`
+	SourceID      string `json:"sourceId"`
+	DestinationID string `json:"destinationId"`
+	Description   string `json:"description,omitempty"`
+	Technology    string `json:"technology,omitempty"`
+	Tags          string `json:"tags"`
+	// Source and Destination identify the elements in the DSL.
+	Source      string `json:"-"`
+	Destination string `json:"-"`
+}
+
+type StructurizrViews struct {
+	SystemLandscapeViews []*StructurizrView       `json:"systemLandscapeViews,omitempty"`
+	ContainerViews       []*StructurizrView       `json:"containerViews,omitempty"`
+	Configuration        StructurizrConfiguration `json:"configuration"`
+}
+
+type StructurizrView struct {
+	Key              string                   `json:"key"`
+	SoftwareSystemID string                   `json:"softwareSystemId,omitempty"`
+	Description      string                   `json:"description,omitempty"`
+	AutomaticLayout  StructurizrLayout        `json:"automaticLayout"`
+	Elements         []StructurizrViewElement `json:"elements"`
+	Relationships    []StructurizrViewElement `json:"relationships"`
+	// SoftwareSystem identifies the software system of container views in
+	// the DSL.
+	SoftwareSystem string `json:"-"`
+}
+
+type StructurizrLayout struct {
+	RankDirection  string `json:"rankDirection"`
+	RankSeparation int    `json:"rankSeparation"`
+	NodeSeparation int    `json:"nodeSeparation"`
+	EdgeSeparation int    `json:"edgeSeparation"`
+	Vertices       bool   `json:"vertices"`
+	Implementation string `json:"implementation"`
+}
+
+type StructurizrViewElement struct {
+	ID string `json:"id"`
+}
+
+type StructurizrConfiguration struct {
+	Styles StructurizrStyles `json:"styles"`
+}
+
+type StructurizrStyles struct {
+	Elements []StructurizrElementStyle `json:"elements"`
+}
+
+type StructurizrElementStyle struct {
+	Tag        string `json:"tag"`
+	Shape      string `json:"shape,omitempty"`
+	Background string `json:"background,omitempty"`
+	Color      string `json:"color,omitempty"`
+}
+
+// structurizrStyles style persons, databases and external systems.
+var structurizrStyles = []StructurizrElementStyle{
+	{Tag: "Person", Shape: "Person"},
+	{Tag: "Database", Shape: "Cylinder"},
+	{Tag: "External", Background: "#999999", Color: "#ffffff"},
+}
+
+// STRUCTURIZR_TPL_DSL renders a workspace in the Structurizr DSL.
+const STRUCTURIZR_TPL_DSL = `
+{{- define "relationships" }}
+{{- range .Relationships }}
+        {{ .Source }} -> {{ .Destination }} {{ quote .Description }} {{ quote .Technology }}
+{{- end }}
+{{- end -}}
+workspace {{ quote .Name }} {{ quote .Description }} {
+
+    !impliedRelationships false
+
+    model {
+{{- range .Model.People }}
+        {{ .Identifier }} = person {{ quote .Name }} {{ quote .Description }} {{ quote (join .CustomTags) }}
+{{- end }}
+{{- range .Model.SoftwareSystems }}
+        {{ .Identifier }} = softwareSystem {{ quote .Name }} {{ quote .Description }} {{ quote (join .CustomTags) }} {
+{{- range .Containers }}
+            {{ .Identifier }} = container {{ quote .Name }} {{ quote .Description }} {{ quote .Technology }} {{ quote (join .CustomTags) }}
+{{- end }}
+        }
+{{- end }}
+{{- range .Model.People }}{{ template "relationships" . }}{{ end }}
+{{- range .Model.SoftwareSystems }}
+{{- template "relationships" . }}
+{{- range .Containers }}{{ template "relationships" . }}{{ end }}
+{{- end }}
+    }
+
+    views {
+{{- range .Views.SystemLandscapeViews }}
+        systemLandscape {{ quote .Key }} {{ quote .Description }} {
+            include *
+            autoLayout
+        }
+{{- end }}
+{{- range .Views.ContainerViews }}
+        container {{ .SoftwareSystem }} {{ quote .Key }} {{ quote .Description }} {
+            include *
+            autoLayout
+        }
+{{- end }}
+
+        styles {
+{{- range .Views.Configuration.Styles.Elements }}
+            element {{ quote .Tag }} {
+{{- if .Shape }}
+                shape {{ .Shape }}
+{{- end }}
+{{- if .Background }}
+                background {{ .Background }}
+{{- end }}
+{{- if .Color }}
+                color {{ .Color }}
+{{- end }}
+            }
+{{- end }}
+        }
+    }
+}
+`
+
+var structurizrTemplate = template.Must(template.New("").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+	"join": func(values []string) string {
+		return strings.Join(values, ",")
+	},
+}).Parse(STRUCTURIZR_TPL_DSL))
+
+// invalidIdentifierChars are all characters not allowed in DSL identifiers.
+var invalidIdentifierChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// structurizrBuilder numbers the elements and relationships of a workspace.
+type structurizrBuilder struct {
+	workspace   *StructurizrWorkspace
+	elements    map[string]*StructurizrElement
+	parents     map[string]string
+	identifiers []string
+	nextID      int
+}
+
+// StructurizrWorkspaceOf builds a workspace of all persons, systems and
+// containers of the landscape with a system landscape view and a container
+// view of every system with containers.
+func StructurizrWorkspaceOf(landscape *catalog.Landscape) *StructurizrWorkspace {
+	b := &structurizrBuilder{
+		workspace: &StructurizrWorkspace{
+			Name:        "c4stage",
+			Description: "Architecture of the c4stage catalog",
+		},
+		elements: make(map[string]*StructurizrElement),
+		parents:  make(map[string]string),
+	}
+
+	for _, system := range landscape.Systems {
+		prefix := "s"
+		if system.Type == "person" {
+			prefix = "p"
+		}
+
+		element := b.element(system.Ref(), prefix, system.EntityEnvelope)
+		switch system.Type {
+		case "person":
+			element.Tags = tagsOf("Element,Person", element.CustomTags)
+			element.Location = "Unspecified"
+			b.workspace.Model.People = append(b.workspace.Model.People, element)
+		case "external":
+			element.CustomTags = append(element.CustomTags, "External")
+			element.Tags = tagsOf("Element,Software System", element.CustomTags)
+			element.Location = "External"
+			b.workspace.Model.SoftwareSystems = append(b.workspace.Model.SoftwareSystems, element)
+		default:
+			element.Tags = tagsOf("Element,Software System", element.CustomTags)
+			element.Location = "Internal"
+			b.workspace.Model.SoftwareSystems = append(b.workspace.Model.SoftwareSystems, element)
+		}
+	}
+
+	for _, container := range landscape.Containers {
+		system, ok := b.elements[catalog.EntityRef("System", container.System)]
+		if !ok || system.Location == "Unspecified" {
+			continue
+		}
+
+		element := b.element(container.Ref(), "c", container.EntityEnvelope)
+		if container.Type == "database" {
+			element.CustomTags = append(element.CustomTags, "Database")
+		}
+		element.Technology = container.Type
+		element.Tags = tagsOf("Element,Container", element.CustomTags)
+		system.Containers = append(system.Containers, element)
+		b.parents[element.ID] = system.ID
+	}
+
+	b.relationships(landscape)
+	b.views()
+
+	return b.workspace
+}
+
+func (b *structurizrBuilder) element(ref string, prefix string, envelope catalog.EntityEnvelope) *StructurizrElement {
+	element := &StructurizrElement{
+		ID:          b.id(),
+		Identifier:  b.identifier(prefix, envelope.Name),
+		Name:        envelope.Title,
+		Description: envelope.Description,
+	}
+	if element.Name == "" {
+		element.Name = envelope.Name
+	}
+	for _, tag := range append(slices.Clone(envelope.Tags), envelope.Lifecycle) {
+		if tag != "" && !slices.Contains(element.CustomTags, tag) {
+			element.CustomTags = append(element.CustomTags, tag)
+		}
+	}
+
+	b.elements[ref] = element
+	return element
+}
+
+func (b *structurizrBuilder) id() string {
+	b.nextID++
+	return strconv.Itoa(b.nextID)
+}
+
+// identifier builds a unique DSL identifier from the name of an entity.
+func (b *structurizrBuilder) identifier(prefix string, name string) string {
+	identifier := prefix + "_" + invalidIdentifierChars.ReplaceAllString(name, "_")
+	unique := identifier
+	for i := 2; slices.Contains(b.identifiers, unique); i++ {
+		unique = fmt.Sprintf("%v_%v", identifier, i)
+	}
+	b.identifiers = append(b.identifiers, unique)
+	return unique
+}
+
+// relationships adds all dependencies and uses of APIs, resolved to the
+// containers or systems providing them, as relationships.
+func (b *structurizrBuilder) relationships(landscape *catalog.Landscape) {
+	apis := make(map[string]catalog.API)
+	for _, api := range landscape.APIs {
+		apis[api.Ref()] = api
+	}
+	usages := make(map[string]map[string]catalog.Usage)
+	for _, container := range landscape.Containers {
+		usages[container.Ref()] = container.Usages
+	}
+
+	providers := make(map[string][]string)
+	for _, relation := range landscape.Relations {
+		if relation.Type == "PROVIDES" && strings.HasPrefix(relation.Source, "component:") {
+			providers[relation.Target] = append(providers[relation.Target], relation.Source)
+		}
+	}
+
+	for _, relation := range landscape.Relations {
+		switch relation.Type {
+		case "DEPENDS_ON":
+			b.relationship(relation.Source, relation.Target, AsRelation(relation.Type), "")
+		case "CONSUMES":
+			api, ok := apis[relation.Target]
+			if !ok {
+				continue
+			}
+			usage := usages[relation.Source][api.Name]
+			technology := usage.Technology
+			if technology == "" {
+				technology = AsTechnology(api.Type)
+			}
+
+			targets := providers[api.Ref()]
+			if len(targets) == 0 && api.System != "" {
+				targets = []string{catalog.EntityRef("System", api.System)}
+			}
+			for _, target := range targets {
+				b.relationship(relation.Source, target, AsUsage(api.Name, usage.Description), technology)
+			}
+		}
+	}
+}
+
+func (b *structurizrBuilder) relationship(sourceRef string, targetRef string, description string, technology string) {
+	source, ok := b.elements[sourceRef]
+	if !ok {
+		return
+	}
+	target, ok := b.elements[targetRef]
+	if !ok || source == target {
+		return
+	}
+
+	// Structurizr does not allow relationships between parents and children
+	if b.parents[source.ID] == target.ID || b.parents[target.ID] == source.ID {
+		return
+	}
+
+	for _, relationship := range source.Relationships {
+		if relationship.DestinationID == target.ID && relationship.Description == description {
+			return
+		}
+	}
+
+	source.Relationships = append(source.Relationships, &StructurizrRelationship{
+		ID:            b.id(),
+		SourceID:      source.ID,
+		DestinationID: target.ID,
+		Description:   description,
+		Technology:    technology,
+		Tags:          "Relationship",
+		Source:        source.Identifier,
+		Destination:   target.Identifier,
+	})
+}
+
+// views adds the system landscape view and the container views.
+func (b *structurizrBuilder) views() {
+	model := b.workspace.Model
+	layout := StructurizrLayout{
+		RankDirection:  "TopBottom",
+		RankSeparation: 300,
+		NodeSeparation: 300,
+		Implementation: "Graphviz",
+	}
+
+	landscapeView := &StructurizrView{
+		Key:             "Landscape",
+		Description:     "System landscape",
+		AutomaticLayout: layout,
+	}
+	var landscapeElements []*StructurizrElement
+	landscapeElements = append(landscapeElements, model.People...)
+	landscapeElements = append(landscapeElements, model.SoftwareSystems...)
+	addToView(landscapeView, landscapeElements, landscapeElements)
+	b.workspace.Views.SystemLandscapeViews = append(b.workspace.Views.SystemLandscapeViews, landscapeView)
+
+	for _, system := range model.SoftwareSystems {
+		if len(system.Containers) == 0 {
+			continue
+		}
+
+		containerView := &StructurizrView{
+			Key:              "Containers_" + strings.TrimPrefix(system.Identifier, "s_"),
+			SoftwareSystemID: system.ID,
+			SoftwareSystem:   system.Identifier,
+			Description:      "Containers of " + system.Name,
+			AutomaticLayout:  layout,
+		}
+
+		// the containers of the system and all people and systems they use
+		// or are used by
+		elements := slices.Clone(system.Containers)
+		for _, other := range landscapeElements {
+			if other == system {
+				continue
+			}
+			related := slices.ContainsFunc(system.Containers, func(container *StructurizrElement) bool {
+				return relates(container, other) || relates(other, container)
+			})
+			if related {
+				elements = append(elements, other)
+			}
+		}
+
+		var sources []*StructurizrElement
+		sources = append(sources, landscapeElements...)
+		sources = append(sources, system.Containers...)
+		addToView(containerView, elements, sources)
+		b.workspace.Views.ContainerViews = append(b.workspace.Views.ContainerViews, containerView)
+	}
+
+	b.workspace.Views.Configuration.Styles.Elements = structurizrStyles
+}
+
+// addToView adds the elements and all relationships between them, taken
+// from the sources, to the view.
+func addToView(view *StructurizrView, elements []*StructurizrElement, sources []*StructurizrElement) {
+	var ids []string
+	for _, element := range elements {
+		ids = append(ids, element.ID)
+		view.Elements = append(view.Elements, StructurizrViewElement{ID: element.ID})
+	}
+	for _, source := range sources {
+		if !slices.Contains(ids, source.ID) {
+			continue
+		}
+		for _, relationship := range source.Relationships {
+			if slices.Contains(ids, relationship.DestinationID) {
+				view.Relationships = append(view.Relationships, StructurizrViewElement{ID: relationship.ID})
+			}
+		}
+	}
+}
+
+func relates(source *StructurizrElement, target *StructurizrElement) bool {
+	return slices.ContainsFunc(source.Relationships, func(relationship *StructurizrRelationship) bool {
+		return relationship.DestinationID == target.ID
+	})
+}
+
+func tagsOf(defaultTags string, customTags []string) string {
+	return strings.Join(append([]string{defaultTags}, customTags...), ",")
+}
+
+// ExportToStructurizrDSL exports the workspace in the Structurizr DSL.
+func ExportToStructurizrDSL(workspace *StructurizrWorkspace, w io.Writer) error {
+	err := structurizrTemplate.Execute(w, workspace)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package c4
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestStructurizrWorkspaceOf(t *testing.T) {
+	is := is.New(t)
+
+	workspace := StructurizrWorkspaceOf(testLandscape())
+
+	is.Equal(len(workspace.Model.People), 1)
+	is.Equal(workspace.Model.People[0].Tags, "Element,Person")
+	is.Equal(len(workspace.Model.SoftwareSystems), 2)
+
+	shop := workspace.Model.SoftwareSystems[0]
+	is.Equal(shop.Tags, "Element,Software System,core")
+	is.Equal(len(shop.Containers), 3)
+	is.Equal(shop.Containers[1].Tags, "Element,Container,Database")
+
+	// relation of system to own container is dropped
+	is.Equal(len(shop.Relationships), 0)
+
+	api := shop.Containers[0]
+	is.Equal(len(api.Relationships), 2)
+	is.Equal(api.Relationships[1].DestinationID, workspace.Model.SoftwareSystems[1].ID)
+	is.Equal(api.Relationships[1].Description, "pays orders via payments-api")
+	is.Equal(api.Relationships[1].Technology, "REST/JSON")
+
+	is.Equal(len(workspace.Views.SystemLandscapeViews), 1)
+	is.Equal(len(workspace.Views.SystemLandscapeViews[0].Elements), 3)
+	is.Equal(len(workspace.Views.ContainerViews), 1)
+
+	containerView := workspace.Views.ContainerViews[0]
+	is.Equal(containerView.SoftwareSystemID, shop.ID)
+	is.Equal(len(containerView.Elements), 4)
+	is.Equal(len(containerView.Relationships), 2)
+}
+
+func TestExportToStructurizrDSL(t *testing.T) {
+	is := is.New(t)
+
+	sw := bytes.NewBufferString("")
+	err := ExportToStructurizrDSL(StructurizrWorkspaceOf(testLandscape()), sw)
+	dsl := sw.String()
+
+	is.NoErr(err)
+	is.True(strings.HasPrefix(dsl, `workspace "c4stage"`))
+	is.True(strings.Contains(dsl, `p_customer = person "Customer" "" ""`))
+	is.True(strings.Contains(dsl, `s_psp = softwareSystem "PSP" "" "External" {`))
+	is.True(strings.Contains(dsl, `c_shop_db = container "Shop DB" "" "database" "Database"`))
+	is.True(strings.Contains(dsl, `c_shop_api -> s_psp "pays orders via payments-api" "REST/JSON"`))
+	is.True(strings.Contains(dsl, `container s_shop "Containers_shop" "Containers of Shop" {`))
+}
+
+func TestStructurizrWorkspaceJSON(t *testing.T) {
+	is := is.New(t)
+
+	data, err := json.Marshal(StructurizrWorkspaceOf(testLandscape()))
+	is.NoErr(err)
+
+	var workspace map[string]any
+	is.NoErr(json.Unmarshal(data, &workspace))
+	views := workspace["views"].(map[string]any)
+	is.True(views["systemLandscapeViews"] != nil)
+	is.True(views["containerViews"] != nil)
+	is.True(!strings.Contains(string(data), "Identifier"))
+}
+
+func TestStructurizrIdentifiersAreUnique(t *testing.T) {
+	is := is.New(t)
+
+	b := &structurizrBuilder{}
+
+	is.Equal(b.identifier("c", "shop-api"), "c_shop_api")
+	is.Equal(b.identifier("c", "shop_api"), "c_shop_api_2")
+}
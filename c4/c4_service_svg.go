@@ -0,0 +1,272 @@
+package c4
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"text/template"
+)
+
+// SVG_TPL_C4 renders a laid out model as SVG image in the colors of the C4
+// model.
+const SVG_TPL_C4 = `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{ scaled .Width }}" height="{{ scaled .Height }}" viewBox="0 0 {{ .Width }} {{ .Height }}" font-family="Arial, Helvetica, sans-serif">
+  <defs>
+    <marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse">
+      <path d="M 0 0 L 10 5 L 0 10 z" fill="context-stroke"/>
+    </marker>
+  </defs>
+  <rect width="100%" height="100%" fill="white"/>
+{{- range .Boundaries }}
+  <g id="{{ xml .ID }}">
+    <rect x="{{ .X }}" y="{{ .Y }}" width="{{ .Width }}" height="{{ .Height }}" rx="4" fill="none" stroke="{{ boundaryColor . }}" stroke-width="1.5" stroke-dasharray="8 4"/>
+    <text x="{{ add .X 10 }}" y="{{ add .Y 20 }}" font-size="14" font-weight="bold" fill="{{ boundaryColor . }}">{{ xml .Title }}</text>
+    <text x="{{ add .X 10 }}" y="{{ add .Y 34 }}" font-size="11" fill="{{ boundaryColor . }}">{{ xml .Stereotype }}</text>
+  </g>
+{{- end }}
+{{- range .Nodes }}
+  <g id="{{ xml .ID }}">
+{{- if eq .Kind "person" }}
+    <circle cx="{{ .CenterX }}" cy="{{ add .Y 18 }}" r="18" fill="{{ fill . }}"/>
+    <rect x="{{ .X }}" y="{{ add .Y 30 }}" width="{{ .Width }}" height="{{ add .Height -30 }}" rx="20" fill="{{ fill . }}"/>
+{{- else if eq .Kind "database" }}
+    <path d="M {{ .X }} {{ add .Y 12 }} a {{ half .Width }} 12 0 0 0 {{ .Width }} 0 v {{ add .Height -24 }} a {{ half .Width }} 12 0 0 1 -{{ .Width }} 0 z" fill="{{ fill . }}"/>
+    <ellipse cx="{{ .CenterX }}" cy="{{ add .Y 12 }}" rx="{{ half .Width }}" ry="12" fill="{{ fill . }}" stroke="white" stroke-opacity="0.5"/>
+{{- else if eq .Kind "api" }}
+    <polygon points="{{ octagon . }}" fill="{{ fill . }}"/>
+{{- else }}
+    <rect x="{{ .X }}" y="{{ .Y }}" width="{{ .Width }}" height="{{ .Height }}" rx="8" fill="{{ fill . }}"/>
+{{- end }}
+{{- $color := textColor . }}
+{{- range .Lines }}
+    <text x="{{ .X }}" y="{{ .Y }}" text-anchor="middle" font-size="{{ .Size }}"{{ if .Bold }} font-weight="bold"{{ end }} fill="{{ $color }}">{{ xml .Text }}</text>
+{{- end }}
+  </g>
+{{- end }}
+{{- range .Edges }}
+{{- $edge := . }}
+  <g>
+    <line x1="{{ .Start.X }}" y1="{{ .Start.Y }}" x2="{{ .End.X }}" y2="{{ .End.Y }}" stroke="{{ stroke . }}" stroke-width="1.5" stroke-dasharray="6 4" marker-end="url(#arrow)"/>
+{{- with .Middle }}
+    <text x="{{ .X }}" y="{{ .Y }}" text-anchor="middle" font-size="12" fill="#333333" stroke="white" stroke-width="4" paint-order="stroke">{{ xml $edge.Label }}</text>
+{{- end }}
+{{- if .Technology }}
+{{- with .Middle }}
+    <text x="{{ .X }}" y="{{ add .Y 14 }}" text-anchor="middle" font-size="11" font-style="italic" fill="#666666" stroke="white" stroke-width="4" paint-order="stroke">[{{ xml $edge.Technology }}]</text>
+{{- end }}
+{{- end }}
+  </g>
+{{- end }}
+</svg>
+`
+
+// kindColors are the colors of the elements by kind, as in the C4 model.
+var kindColors = map[string]string{
+	"person":    "#08427B",
+	"system":    "#1168BD",
+	"external":  "#999999",
+	"container": "#438DD5",
+	"database":  "#438DD5",
+	"component": "#85BBF0",
+	"api":       "SlateGray",
+}
+
+const (
+	svgDescriptionWidth = 32
+	svgDescriptionLines = 3
+)
+
+// svgLine is a line of text of a node.
+type svgLine struct {
+	X    float64
+	Y    float64
+	Size int
+	Bold bool
+	Text string
+}
+
+type svgExporter struct {
+	template *template.Template
+}
+
+func newSVGExporter() *svgExporter {
+	t, err := template.New("").Funcs(template.FuncMap{
+		"xml":           xmlEscape,
+		"scaled":        func(a float64) float64 { return a },
+		"add":           func(a float64, b float64) float64 { return a + b },
+		"half":          func(a float64) float64 { return a / 2 },
+		"fill":          svgFill,
+		"textColor":     svgTextColor,
+		"boundaryColor": svgBoundaryColor,
+		"stroke":        svgStroke,
+		"octagon":       svgOctagon,
+	}).Parse(SVG_TPL_C4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return &svgExporter{
+		template: t,
+	}
+}
+
+// ExportToSVG lays out the model and exports it as SVG image.
+func (e *svgExporter) ExportToSVG(c4Model *C4DiagramModel, w io.Writer) error {
+	scale := c4Model.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+
+	t, err := e.template.Clone()
+	if err != nil {
+		return err
+	}
+	t.Funcs(template.FuncMap{
+		"scaled": func(a float64) float64 { return a * scale },
+	})
+
+	return t.Execute(w, LayoutOf(c4Model))
+}
+
+// Stereotype is the kind of the node with its technology, like
+// `[Container: go]`.
+func (n LayoutNode) Stereotype() string {
+	kind := map[string]string{
+		"person":    "Person",
+		"system":    "Software System",
+		"external":  "External System",
+		"container": "Container",
+		"database":  "Database",
+		"component": "Component",
+		"api":       "API",
+	}[n.Kind]
+
+	if n.Kind == "person" {
+		return stereotype(kind, "")
+	}
+	return stereotype(kind, n.Technology)
+}
+
+// stereotype is the kind of an element with its technology, like
+// `[Container: go]`.
+func stereotype(kind string, technology string) string {
+	if technology == "" {
+		return fmt.Sprintf("[%v]", kind)
+	}
+	return fmt.Sprintf("[%v: %v]", kind, technology)
+}
+
+// Stereotype is the kind of the boundary, like `[System]`.
+func (b LayoutBoundary) Stereotype() string {
+	switch b.Kind {
+	case "container":
+		return "[Container]"
+	case "deployment":
+		return "[Deployment Node]"
+	default:
+		return "[System]"
+	}
+}
+
+// Lines are the title, stereotype and wrapped description of the node.
+func (n LayoutNode) Lines() []svgLine {
+	y := n.Y + 36
+	if n.Kind == "person" {
+		y = n.Y + 60
+	} else if n.Kind == "database" {
+		y = n.Y + 44
+	}
+
+	lines := []svgLine{
+		{X: n.CenterX(), Y: y, Size: 15, Bold: true, Text: n.Title},
+		{X: n.CenterX(), Y: y + 18, Size: 11, Text: n.Stereotype()},
+	}
+	for i, text := range wrapText(n.Description, svgDescriptionWidth, svgDescriptionLines) {
+		lines = append(lines, svgLine{X: n.CenterX(), Y: y + 40 + float64(i)*15, Size: 12, Text: text})
+	}
+	return lines
+}
+
+// wrapText wraps the text into lines of at most width characters, cutting
+// it off after the given number of lines.
+func wrapText(text string, width int, maxLines int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] += " …"
+	}
+	return lines
+}
+
+func svgFill(n *LayoutNode) string {
+	return elementColor(n.Kind, n.Tags)
+}
+
+func svgTextColor(n *LayoutNode) string {
+	if n.Kind == "component" && tagColor(tagElementColors, n.Tags) == "" {
+		return "black"
+	}
+	return "white"
+}
+
+func svgBoundaryColor(b *LayoutBoundary) string {
+	if color := tagColor(tagElementColors, b.Tags); color != "" {
+		return color
+	}
+	return "#444444"
+}
+
+func svgStroke(e *LayoutEdge) string {
+	return relationColor(e.Tags)
+}
+
+// elementColor is the color of the first tag of the element with a color,
+// or else the color of its kind.
+func elementColor(kind string, tags []string) string {
+	if color := tagColor(tagElementColors, tags); color != "" {
+		return color
+	}
+	return kindColors[kind]
+}
+
+// relationColor is the color of the first tag of the relation with a
+// color, or else gray.
+func relationColor(tags []string) string {
+	if color := tagColor(tagRelationColors, tags); color != "" {
+		return color
+	}
+	return "#707070"
+}
+
+// svgOctagon is the points of an eight sided shape filling the node.
+func svgOctagon(n *LayoutNode) string {
+	const cut = 24.0
+	x1, x2, x3, x4 := n.X, n.X+cut, n.X+n.Width-cut, n.X+n.Width
+	y1, y2, y3, y4 := n.Y, n.Y+cut, n.Y+n.Height-cut, n.Y+n.Height
+	return fmt.Sprintf("%v,%v %v,%v %v,%v %v,%v %v,%v %v,%v %v,%v %v,%v",
+		x2, y1, x3, y1, x4, y2, x4, y3, x3, y4, x2, y4, x1, y3, x1, y2)
+}
+
+func xmlEscape(text string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package c4
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestExportToSVG(t *testing.T) {
+	// Arrange
+	is := is.New(t)
+	e := newSVGExporter()
+
+	sw := bytes.NewBufferString("")
+	m := &C4DiagramModel{Scale: 0.5}
+	m.AddSystem(&System{ID: "s1", Label: "customer", Title: "Customer", Type: "person"})
+	m.AddSystem(&System{ID: "s2", Label: "shop", Title: "Shop & Co", Tags: []string{"deprecated"}})
+	m.AddContainer(&Container{ID: "c1", Label: "shop-db", Title: "Shop DB", System: "shop", Type: "database", Technology: "postgres"})
+	m.AddRelation(Relation{SourceID: "s1", TargetID: "c1", Label: "reads", Technology: "SQL", Tags: []string{"cycle"}})
+	m.PostProcess()
+
+	// Act
+	err := e.ExportToSVG(m, sw)
+	svg := sw.String()
+
+	// Assert
+	is.NoErr(err)
+	is.NoErr(xml.Unmarshal(sw.Bytes(), new(struct{})))
+	is.True(strings.Contains(svg, `<circle`))
+	is.True(strings.Contains(svg, `<ellipse`))
+	is.True(strings.Contains(svg, `>Shop &amp; Co</text>`))
+	is.True(strings.Contains(svg, `stroke="DarkCyan"`))
+	is.True(strings.Contains(svg, `>[Database: postgres]</text>`))
+	is.True(strings.Contains(svg, `stroke="Crimson"`))
+	is.True(strings.Contains(svg, `>[SQL]</text>`))
+}
+
+func TestWrapText(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(wrapText("places orders of customers", 12, 3), []string{"places", "orders of", "customers"})
+	is.Equal(wrapText("a b c d", 1, 2), []string{"a", "b …"})
+	is.Equal(len(wrapText("", 10, 3)), 0)
+}
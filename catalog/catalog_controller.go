@@ -1,11 +1,13 @@
 package catalog
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.io/remast/c4stage/shared"
 	"github.io/remast/c4stage/shared/paged"
+	"schneider.vip/problem"
 )
 
 type CatalogController struct {
@@ -18,6 +20,14 @@ type systemsModel struct {
 	*paged.Page `json:"page"`
 }
 
+type findingsModel struct {
+	Data []Finding `json:"data"`
+}
+
+type snapshotsModel struct {
+	Data []Snapshot `json:"data"`
+}
+
 func (c *CatalogController) RegisterProtected(router chi.Router) {
 }
 
@@ -26,6 +36,9 @@ func (c *CatalogController) RegisterOpen(router chi.Router) {
 	router.Mount("/catalog", r)
 
 	r.Get("/", c.HandleGetSystems())
+	r.Get("/snapshots", c.HandleGetSnapshots())
+	r.Get("/diff", c.HandleGetDiff())
+	r.Get("/lint", c.HandleGetLint())
 }
 
 func (c *CatalogController) HandleGetSystems() http.HandlerFunc {
@@ -47,3 +60,94 @@ func (c *CatalogController) HandleGetSystems() http.HandlerFunc {
 		shared.RenderJSON(w, systemsModel)
 	}
 }
+
+func (c *CatalogController) HandleGetSnapshots() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshots, err := c.Repository.FindSnapshots(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		snapshotsModel := snapshotsModel{
+			Data: snapshots,
+		}
+
+		shared.RenderJSON(w, snapshotsModel)
+	}
+}
+
+// HandleGetDiff compares the snapshot given by query parameter `from` with
+// the snapshot the request is scoped to, which by default is the live graph.
+func (c *CatalogController) HandleGetDiff() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		from := r.URL.Query().Get("from")
+		if from == "" {
+			http.Error(w, problem.New(problem.Title("missing snapshot to compare from")).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		if from != shared.LiveSnapshot {
+			snapshot, err := c.Repository.FindSnapshot(r.Context(), from)
+			if err != nil {
+				shared.RenderProblemJSON(w, isProduction, err)
+				return
+			}
+			if snapshot == nil {
+				message := fmt.Sprintf("snapshot %v not found", from)
+				http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+				return
+			}
+		}
+
+		fromLandscape, err := c.Repository.FindLandscape(shared.ContextWithSnapshot(r.Context(), from))
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		to := shared.SnapshotFromContext(r.Context())
+		toLandscape, err := c.Repository.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		diff := Diff(from, fromLandscape, to, toLandscape)
+
+		if r.URL.Query().Get("format") == "markdown" {
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			err = diff.Markdown(w)
+			if err != nil {
+				shared.RenderProblemJSON(w, isProduction, err)
+			}
+			return
+		}
+
+		shared.RenderJSON(w, diff)
+	}
+}
+
+// HandleGetLint checks the catalog for dangling references, orphaned
+// entities and missing documentation.
+func (c *CatalogController) HandleGetLint() http.HandlerFunc {
+	isProduction := c.Config.IsProduction()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		landscape, err := c.Repository.FindLandscape(r.Context())
+		if err != nil {
+			shared.RenderProblemJSON(w, isProduction, err)
+			return
+		}
+
+		findingsModel := findingsModel{
+			Data: Lint(landscape),
+		}
+
+		shared.RenderJSON(w, findingsModel)
+	}
+}
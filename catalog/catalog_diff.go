@@ -0,0 +1,222 @@
+package catalog
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// LandscapeDiff lists the changes between two snapshots of the catalog.
+type LandscapeDiff struct {
+	From       string       `json:"from"`
+	To         string       `json:"to"`
+	Systems    EntityDiff   `json:"systems"`
+	Containers EntityDiff   `json:"containers"`
+	APIs       EntityDiff   `json:"apis"`
+	Relations  RelationDiff `json:"relations"`
+}
+
+type EntityDiff struct {
+	Added    []ChangedEntity `json:"added"`
+	Removed  []ChangedEntity `json:"removed"`
+	Modified []ChangedEntity `json:"modified"`
+}
+
+type ChangedEntity struct {
+	Ref     string        `json:"ref"`
+	Title   string        `json:"title"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type RelationDiff struct {
+	Added   []Relation `json:"added"`
+	Removed []Relation `json:"removed"`
+}
+
+// comparableEntity is an entity reduced to the fields compared by the diff.
+type comparableEntity struct {
+	ref    string
+	title  string
+	fields map[string]string
+}
+
+// fieldOrder is the order in which changed fields are reported.
+var fieldOrder = []string{"title", "description", "type", "lifecycle", "tags", "system"}
+
+// Diff compares the landscape from with the landscape to.
+func Diff(fromID string, from *Landscape, toID string, to *Landscape) *LandscapeDiff {
+	diff := &LandscapeDiff{
+		From: fromID,
+		To:   toID,
+	}
+
+	diff.Systems = diffEntities(comparableSystems(from), comparableSystems(to))
+	diff.Containers = diffEntities(comparableContainers(from), comparableContainers(to))
+	diff.APIs = diffEntities(comparableAPIs(from), comparableAPIs(to))
+
+	for _, relation := range to.Relations {
+		if !slices.Contains(from.Relations, relation) {
+			diff.Relations.Added = append(diff.Relations.Added, relation)
+		}
+	}
+	for _, relation := range from.Relations {
+		if !slices.Contains(to.Relations, relation) {
+			diff.Relations.Removed = append(diff.Relations.Removed, relation)
+		}
+	}
+
+	return diff
+}
+
+// IsEmpty checks whether nothing changed.
+func (d LandscapeDiff) IsEmpty() bool {
+	return d.Systems.IsEmpty() &&
+		d.Containers.IsEmpty() &&
+		d.APIs.IsEmpty() &&
+		len(d.Relations.Added) == 0 &&
+		len(d.Relations.Removed) == 0
+}
+
+func (d EntityDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// Markdown writes the diff as change log in Markdown.
+func (d LandscapeDiff) Markdown(w io.Writer) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# Architecture Changes\n\nChanges from `%v` to `%v`.\n", d.From, d.To)
+
+	if d.IsEmpty() {
+		sb.WriteString("\nNo changes.\n")
+	}
+
+	writeEntityDiffMarkdown(&sb, "Systems", d.Systems)
+	writeEntityDiffMarkdown(&sb, "Containers", d.Containers)
+	writeEntityDiffMarkdown(&sb, "APIs", d.APIs)
+
+	if len(d.Relations.Added) > 0 || len(d.Relations.Removed) > 0 {
+		sb.WriteString("\n## Relations\n\n")
+		for _, relation := range d.Relations.Added {
+			fmt.Fprintf(&sb, "- Added `%v` %v `%v`\n", relation.Source, relation.Type, relation.Target)
+		}
+		for _, relation := range d.Relations.Removed {
+			fmt.Fprintf(&sb, "- Removed `%v` %v `%v`\n", relation.Source, relation.Type, relation.Target)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeEntityDiffMarkdown(sb *strings.Builder, heading string, diff EntityDiff) {
+	if diff.IsEmpty() {
+		return
+	}
+
+	fmt.Fprintf(sb, "\n## %v\n\n", heading)
+	for _, entity := range diff.Added {
+		fmt.Fprintf(sb, "- Added `%v` %v\n", entity.Ref, entity.Title)
+	}
+	for _, entity := range diff.Removed {
+		fmt.Fprintf(sb, "- Removed `%v` %v\n", entity.Ref, entity.Title)
+	}
+	for _, entity := range diff.Modified {
+		fmt.Fprintf(sb, "- Modified `%v` %v\n", entity.Ref, entity.Title)
+		for _, change := range entity.Changes {
+			fmt.Fprintf(sb, "  - %v: `%v` → `%v`\n", change.Field, change.From, change.To)
+		}
+	}
+}
+
+func diffEntities(from []comparableEntity, to []comparableEntity) EntityDiff {
+	diff := EntityDiff{}
+
+	fromByRef := make(map[string]comparableEntity)
+	for _, entity := range from {
+		fromByRef[entity.ref] = entity
+	}
+	toByRef := make(map[string]comparableEntity)
+	for _, entity := range to {
+		toByRef[entity.ref] = entity
+	}
+
+	for _, toEntity := range to {
+		fromEntity, ok := fromByRef[toEntity.ref]
+		if !ok {
+			diff.Added = append(diff.Added, ChangedEntity{Ref: toEntity.ref, Title: toEntity.title})
+			continue
+		}
+
+		var changes []FieldChange
+		for _, field := range fieldOrder {
+			if fromEntity.fields[field] != toEntity.fields[field] {
+				changes = append(changes, FieldChange{
+					Field: field,
+					From:  fromEntity.fields[field],
+					To:    toEntity.fields[field],
+				})
+			}
+		}
+		if len(changes) > 0 {
+			diff.Modified = append(diff.Modified, ChangedEntity{Ref: toEntity.ref, Title: toEntity.title, Changes: changes})
+		}
+	}
+
+	for _, fromEntity := range from {
+		if _, ok := toByRef[fromEntity.ref]; !ok {
+			diff.Removed = append(diff.Removed, ChangedEntity{Ref: fromEntity.ref, Title: fromEntity.title})
+		}
+	}
+
+	return diff
+}
+
+func comparableEnvelope(ref string, e EntityEnvelope) comparableEntity {
+	return comparableEntity{
+		ref:   ref,
+		title: e.Title,
+		fields: map[string]string{
+			"title":       e.Title,
+			"description": e.Description,
+			"type":        e.Type,
+			"lifecycle":   e.Lifecycle,
+			"tags":        strings.Join(e.Tags, ","),
+		},
+	}
+}
+
+func comparableSystems(l *Landscape) []comparableEntity {
+	var entities []comparableEntity
+	for _, system := range l.Systems {
+		entities = append(entities, comparableEnvelope(system.Ref(), system.EntityEnvelope))
+	}
+	return entities
+}
+
+func comparableContainers(l *Landscape) []comparableEntity {
+	var entities []comparableEntity
+	for _, container := range l.Containers {
+		entity := comparableEnvelope(container.Ref(), container.EntityEnvelope)
+		entity.fields["system"] = container.System
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+func comparableAPIs(l *Landscape) []comparableEntity {
+	var entities []comparableEntity
+	for _, api := range l.APIs {
+		entity := comparableEnvelope(api.Ref(), api.EntityEnvelope)
+		entity.fields["system"] = api.System
+		entities = append(entities, entity)
+	}
+	return entities
+}
@@ -0,0 +1,69 @@
+package catalog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDiffWithoutChanges(t *testing.T) {
+	is := is.New(t)
+
+	l := &Landscape{
+		Systems: []System{{EntityEnvelope: EntityEnvelope{Name: "shop", Title: "Shop"}}},
+	}
+
+	diff := Diff("1", l, "live", l)
+
+	is.True(diff.IsEmpty())
+}
+
+func TestDiffWithChanges(t *testing.T) {
+	is := is.New(t)
+
+	from := &Landscape{
+		Systems: []System{
+			{EntityEnvelope: EntityEnvelope{Name: "shop", Lifecycle: "experimental"}},
+			{EntityEnvelope: EntityEnvelope{Name: "legacy"}},
+		},
+		Relations: []Relation{
+			{Source: "system:shop", Target: "system:legacy", Type: "DEPENDS_ON"},
+		},
+	}
+	to := &Landscape{
+		Systems: []System{
+			{EntityEnvelope: EntityEnvelope{Name: "shop", Lifecycle: "production"}},
+		},
+		Containers: []Container{
+			{EntityEnvelope: EntityEnvelope{Name: "shop-ui"}, System: "shop"},
+		},
+	}
+
+	diff := Diff("1", from, "live", to)
+
+	is.Equal(len(diff.Systems.Removed), 1)
+	is.Equal(diff.Systems.Removed[0].Ref, "system:legacy")
+	is.Equal(len(diff.Systems.Modified), 1)
+	is.Equal(diff.Systems.Modified[0].Changes[0], FieldChange{Field: "lifecycle", From: "experimental", To: "production"})
+	is.Equal(len(diff.Containers.Added), 1)
+	is.Equal(diff.Containers.Added[0].Ref, "component:shop-ui")
+	is.Equal(len(diff.Relations.Removed), 1)
+}
+
+func TestDiffMarkdown(t *testing.T) {
+	is := is.New(t)
+
+	from := &Landscape{}
+	to := &Landscape{
+		APIs: []API{{EntityEnvelope: EntityEnvelope{Name: "orders-api"}}},
+	}
+
+	sw := bytes.NewBufferString("")
+	err := Diff("1", from, "live", to).Markdown(sw)
+
+	is.NoErr(err)
+	is.True(strings.Contains(sw.String(), "## APIs"))
+	is.True(strings.Contains(sw.String(), "- Added `api:orders-api`"))
+}
@@ -2,6 +2,9 @@ package catalog
 
 import (
 	"context"
+	"slices"
+	"strings"
+	"time"
 
 	"github.io/remast/c4stage/shared/paged"
 )
@@ -14,7 +17,11 @@ type EntityEnvelope struct {
 	Kind        string   `json:"kind"`
 	Type        string   `json:"type"`
 	Lifecycle   string   `json:"lifecycle"`
+	Owner       string   `json:"owner"`
+	Domain      string   `json:"domain"`
 	Tags        []string `json:"tags"`
+	// Defined is false for placeholders of entities only referenced by others.
+	Defined bool `json:"defined"`
 }
 
 type System struct {
@@ -26,15 +33,77 @@ type Container struct {
 	EntityEnvelope
 	System       string   `json:"system"`
 	ConsumesAPIs []string `json:"consumesAPIs"`
+	ProvidesAPIs []string `json:"providesAPIs"`
 	DependsOn    []string `json:"dependsOn"`
+	// Deployments maps environments to the path of deployment nodes the
+	// container runs on, like `aws-prod/eks-cluster/orders`.
+	Deployments map[string]string `json:"deployments,omitempty"`
+	// Usages describe how the container uses the APIs it consumes by name.
+	Usages map[string]Usage `json:"usages,omitempty"`
+}
+
+// Usage is the description and technology of the use of an API, like
+// `places orders` over `REST/JSON`.
+type Usage struct {
+	Description string `json:"description,omitempty"`
+	Technology  string `json:"technology,omitempty"`
+}
+
+// SubComponent is a component within a container, like a module of a
+// service. It is the element of C4 component diagrams.
+type SubComponent struct {
+	EntityEnvelope
+	Container string   `json:"container"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// DeploymentNode is infrastructure containers are deployed on, like a
+// Kubernetes cluster or an AWS account. Deployments maps environments to the
+// path of the parent deployment nodes.
+type DeploymentNode struct {
+	EntityEnvelope
+	Deployments map[string]string `json:"deployments,omitempty"`
 }
 
 type API struct {
 	EntityEnvelope
-	System string
+	System string `json:"system"`
+	// Definition is the definition of the API, like an OpenAPI document.
+	Definition string `json:"definition,omitempty"`
+}
+
+// Relation is a directed relation between two entities given as entity refs.
+type Relation struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+// Landscape is the complete graph of a snapshot of the catalog.
+type Landscape struct {
+	Systems         []System
+	Containers      []Container
+	SubComponents   []SubComponent
+	APIs            []API
+	DeploymentNodes []DeploymentNode
+	Relations       []Relation
+}
+
+// Snapshot is a versioned copy of the catalog as imported at a point in time.
+type Snapshot struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Source    string    `json:"source"`
+}
+
+// ImportListener is notified after entities have been imported into the
+// live graph of the catalog.
+type ImportListener interface {
+	Imported(ctx context.Context) error
 }
 
 type CatalogRepository interface {
+	Migrate(ctx context.Context) error
 	Reset(ctx context.Context) error
 
 	FindSystems(
@@ -46,4 +115,149 @@ type CatalogRepository interface {
 		ctx context.Context,
 		entities []any,
 	) error
+
+	FindLandscape(
+		ctx context.Context,
+	) (*Landscape, error)
+
+	MarkViolations(
+		ctx context.Context,
+		violatedRules map[Relation][]string,
+	) error
+
+	CreateSnapshot(
+		ctx context.Context,
+		source string,
+		retention int,
+	) (*Snapshot, error)
+
+	FindSnapshots(
+		ctx context.Context,
+	) ([]Snapshot, error)
+
+	FindSnapshot(
+		ctx context.Context,
+		id string,
+	) (*Snapshot, error)
+
+	FindSnapshotAt(
+		ctx context.Context,
+		at time.Time,
+	) (*Snapshot, error)
+}
+
+var refKinds = map[string]string{
+	"System":    "system",
+	"Component": "component",
+	"API":       "api",
+}
+
+// DeploymentsToProps converts deployments to a list property like
+// `production=aws-prod/eks-cluster`.
+func DeploymentsToProps(deployments map[string]string) []string {
+	var props []string
+	for env, path := range deployments {
+		props = append(props, env+"="+path)
+	}
+	slices.Sort(props)
+	return props
+}
+
+// DeploymentsFromProps converts a list property back to deployments.
+func DeploymentsFromProps(props []string) map[string]string {
+	if len(props) == 0 {
+		return nil
+	}
+
+	deployments := make(map[string]string)
+	for _, prop := range props {
+		env, path, ok := strings.Cut(prop, "=")
+		if ok {
+			deployments[env] = path
+		}
+	}
+	return deployments
+}
+
+// UsagesToProps converts usages to a list property like
+// `orders-api.technology=REST/JSON`.
+func UsagesToProps(usages map[string]Usage) []string {
+	var props []string
+	for api, usage := range usages {
+		if usage.Description != "" {
+			props = append(props, api+".description="+usage.Description)
+		}
+		if usage.Technology != "" {
+			props = append(props, api+".technology="+usage.Technology)
+		}
+	}
+	slices.Sort(props)
+	return props
+}
+
+// UsagesFromProps converts a list property back to usages.
+func UsagesFromProps(props []string) map[string]Usage {
+	if len(props) == 0 {
+		return nil
+	}
+
+	usages := make(map[string]Usage)
+	for _, prop := range props {
+		key, value, ok := strings.Cut(prop, "=")
+		if !ok {
+			continue
+		}
+		i := strings.LastIndex(key, ".")
+		if i == -1 {
+			continue
+		}
+
+		api, field := key[:i], key[i+1:]
+		usage := usages[api]
+		switch field {
+		case "description":
+			usage.Description = value
+		case "technology":
+			usage.Technology = value
+		}
+		usages[api] = usage
+	}
+	return usages
+}
+
+// EntityRef builds the reference of an entity like `system:my-system`
+// from the label of its node and its name.
+func EntityRef(label string, name string) string {
+	kind, ok := refKinds[label]
+	if !ok {
+		kind = strings.ToLower(label)
+	}
+	return kind + ":" + name
+}
+
+// ParseRef splits an entity ref like `system:my-system` into the label of
+// its node and its name.
+func ParseRef(ref string) (string, string) {
+	kind, name, found := strings.Cut(ref, ":")
+	if !found {
+		return "Component", ref
+	}
+	for label, refKind := range refKinds {
+		if refKind == kind {
+			return label, name
+		}
+	}
+	return kind, name
+}
+
+func (s System) Ref() string {
+	return EntityRef("System", s.Name)
+}
+
+func (c Container) Ref() string {
+	return EntityRef("Component", c.Name)
+}
+
+func (a API) Ref() string {
+	return EntityRef("API", a.Name)
 }
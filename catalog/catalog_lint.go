@@ -0,0 +1,141 @@
+package catalog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+var severityOrder = []string{SeverityError, SeverityWarning, SeverityInfo}
+
+// Finding is a problem of an entity of the catalog found by the lint.
+type Finding struct {
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Ref      string `json:"ref"`
+	Message  string `json:"message"`
+}
+
+// Lint checks the landscape for dangling references, orphaned entities
+// and missing documentation.
+func Lint(landscape *Landscape) []Finding {
+	findings := []Finding{}
+
+	for _, system := range landscape.Systems {
+		if !system.Defined {
+			findings = append(findings, undefinedEntity(landscape, system.Ref()))
+			continue
+		}
+
+		if system.Type != "person" && system.Type != "external" && !hasRelation(landscape, system.Ref(), "CONTAINS", true) {
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Rule:     "system-without-containers",
+				Ref:      system.Ref(),
+				Message:  "system contains no containers",
+			})
+		}
+
+		findings = append(findings, lintDescription(system.Ref(), system.EntityEnvelope)...)
+	}
+
+	for _, container := range landscape.Containers {
+		if !container.Defined {
+			findings = append(findings, undefinedEntity(landscape, container.Ref()))
+			continue
+		}
+
+		if container.System == "" {
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Rule:     "container-without-system",
+				Ref:      container.Ref(),
+				Message:  "component belongs to no system",
+			})
+		}
+
+		findings = append(findings, lintDescription(container.Ref(), container.EntityEnvelope)...)
+	}
+
+	for _, api := range landscape.APIs {
+		provided := api.System != "" || hasRelation(landscape, api.Ref(), "PROVIDES", false)
+		if !provided && hasRelation(landscape, api.Ref(), "CONSUMES", false) {
+			findings = append(findings, Finding{
+				Severity: SeverityError,
+				Rule:     "api-without-provider",
+				Ref:      api.Ref(),
+				Message:  "api is consumed but provided by no system",
+			})
+		}
+
+		if !api.Defined {
+			findings = append(findings, undefinedEntity(landscape, api.Ref()))
+			continue
+		}
+
+		findings = append(findings, lintDescription(api.Ref(), api.EntityEnvelope)...)
+	}
+
+	slices.SortStableFunc(findings, func(a Finding, b Finding) int {
+		if c := slices.Index(severityOrder, a.Severity) - slices.Index(severityOrder, b.Severity); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Ref, b.Ref)
+	})
+
+	return findings
+}
+
+func undefinedEntity(landscape *Landscape, ref string) Finding {
+	var referencedBy []string
+	for _, relation := range landscape.Relations {
+		if relation.Target == ref && !slices.Contains(referencedBy, relation.Source) {
+			referencedBy = append(referencedBy, relation.Source)
+		}
+	}
+
+	message := "entity is not defined in the catalog"
+	if len(referencedBy) > 0 {
+		message = fmt.Sprintf("entity is not defined in the catalog but referenced by %v", strings.Join(referencedBy, ", "))
+	}
+
+	return Finding{
+		Severity: SeverityError,
+		Rule:     "undefined-entity",
+		Ref:      ref,
+		Message:  message,
+	}
+}
+
+func lintDescription(ref string, envelope EntityEnvelope) []Finding {
+	if strings.TrimSpace(envelope.Description) != "" {
+		return nil
+	}
+
+	return []Finding{{
+		Severity: SeverityInfo,
+		Rule:     "missing-description",
+		Ref:      ref,
+		Message:  "entity has no description",
+	}}
+}
+
+// hasRelation checks whether the entity with the given ref is source
+// (outgoing) or target of a relation of the given type.
+func hasRelation(landscape *Landscape, ref string, relationType string, outgoing bool) bool {
+	for _, relation := range landscape.Relations {
+		if relation.Type != relationType {
+			continue
+		}
+		if outgoing && relation.Source == ref || !outgoing && relation.Target == ref {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestLintWithCleanLandscape(t *testing.T) {
+	is := is.New(t)
+
+	l := &Landscape{
+		Systems: []System{
+			{EntityEnvelope: EntityEnvelope{Name: "shop", Description: "Web Shop", Defined: true}},
+		},
+		Containers: []Container{
+			{EntityEnvelope: EntityEnvelope{Name: "shop-ui", Description: "UI", Defined: true}, System: "shop"},
+		},
+		Relations: []Relation{
+			{Source: "system:shop", Target: "component:shop-ui", Type: "CONTAINS"},
+		},
+	}
+
+	is.Equal(len(Lint(l)), 0)
+}
+
+func TestLintWithProblems(t *testing.T) {
+	is := is.New(t)
+
+	l := &Landscape{
+		Systems: []System{
+			{EntityEnvelope: EntityEnvelope{Name: "shop", Description: "Web Shop", Defined: true}},
+			{EntityEnvelope: EntityEnvelope{Name: "paymnt"}},
+		},
+		Containers: []Container{
+			{EntityEnvelope: EntityEnvelope{Name: "shop-ui", Defined: true}},
+		},
+		APIs: []API{
+			{EntityEnvelope: EntityEnvelope{Name: "orders-api"}},
+		},
+		Relations: []Relation{
+			{Source: "component:shop-ui", Target: "system:paymnt", Type: "DEPENDS_ON"},
+			{Source: "component:shop-ui", Target: "api:orders-api", Type: "CONSUMES"},
+		},
+	}
+
+	findings := Lint(l)
+
+	is.Equal(len(findings), 6)
+	is.Equal(findings[0], Finding{
+		Severity: SeverityError,
+		Rule:     "api-without-provider",
+		Ref:      "api:orders-api",
+		Message:  "api is consumed but provided by no system",
+	})
+	is.Equal(findings[1].Rule, "undefined-entity")
+	is.Equal(findings[2].Message, "entity is not defined in the catalog but referenced by component:shop-ui")
+	is.Equal(findings[3].Rule, "container-without-system")
+	is.Equal(findings[4].Rule, "system-without-containers")
+	is.Equal(findings[5].Rule, "missing-description")
+}
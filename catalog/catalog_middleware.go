@@ -0,0 +1,83 @@
+package catalog
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.io/remast/c4stage/shared"
+	"schneider.vip/problem"
+)
+
+// SnapshotContext scopes the request to the snapshot of the catalog selected
+// by the query parameter `snapshot` (id of a snapshot) or `at` (timestamp),
+// without both the request is scoped to the live graph.
+func SnapshotContext(repository CatalogRepository, isProduction bool) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			snapshotID := r.URL.Query().Get("snapshot")
+			atParam := r.URL.Query().Get("at")
+
+			if snapshotID != "" && atParam != "" {
+				http.Error(w, problem.New(problem.Title("use either snapshot or at")).JSONString(), http.StatusBadRequest)
+				return
+			}
+
+			var snapshot *Snapshot
+			var err error
+			switch {
+			case snapshotID != "" && snapshotID != shared.LiveSnapshot:
+				snapshot, err = repository.FindSnapshot(r.Context(), snapshotID)
+				if err != nil {
+					shared.RenderProblemJSON(w, isProduction, err)
+					return
+				}
+				if snapshot == nil {
+					message := fmt.Sprintf("snapshot %v not found", snapshotID)
+					http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+					return
+				}
+			case atParam != "":
+				at, err := ParseTimestamp(atParam)
+				if err != nil {
+					message := fmt.Sprintf("invalid timestamp %v", atParam)
+					http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusBadRequest)
+					return
+				}
+				snapshot, err = repository.FindSnapshotAt(r.Context(), at)
+				if err != nil {
+					shared.RenderProblemJSON(w, isProduction, err)
+					return
+				}
+				if snapshot == nil {
+					message := fmt.Sprintf("no snapshot at %v", atParam)
+					http.Error(w, problem.New(problem.Title(message)).JSONString(), http.StatusNotFound)
+					return
+				}
+			}
+
+			if snapshot == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := shared.ContextWithSnapshot(r.Context(), snapshot.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ParseTimestamp parses a timestamp given either as RFC 3339 or as date.
+// A date refers to the end of that day.
+func ParseTimestamp(timestamp string) (time.Time, error) {
+	at, err := time.Parse(time.RFC3339, timestamp)
+	if err == nil {
+		return at, nil
+	}
+
+	day, err := time.Parse(time.DateOnly, timestamp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.Add(24*time.Hour - time.Nanosecond), nil
+}
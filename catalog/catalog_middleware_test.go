@@ -0,0 +1,66 @@
+package catalog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.io/remast/c4stage/shared"
+)
+
+func TestParseTimestampWithRFC3339(t *testing.T) {
+	is := is.New(t)
+
+	at, err := ParseTimestamp("2024-01-31T10:00:00Z")
+
+	is.NoErr(err)
+	is.Equal(at, time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC))
+}
+
+func TestParseTimestampWithDate(t *testing.T) {
+	is := is.New(t)
+
+	at, err := ParseTimestamp("2024-01-31")
+
+	is.NoErr(err)
+	is.Equal(at.Day(), 31)
+	is.Equal(at.Hour(), 23)
+}
+
+func TestParseTimestampInvalid(t *testing.T) {
+	is := is.New(t)
+
+	_, err := ParseTimestamp("last quarter")
+
+	is.True(err != nil)
+}
+
+func TestSnapshotContextWithoutParams(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	var snapshot string
+	h := SnapshotContext(nil, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snapshot = shared.SnapshotFromContext(r.Context())
+	}))
+
+	r, _ := http.NewRequest("GET", "/api/c4/context", nil)
+	h.ServeHTTP(httpRec, r)
+
+	is.Equal(httpRec.Result().StatusCode, http.StatusOK)
+	is.Equal(snapshot, shared.LiveSnapshot)
+}
+
+func TestSnapshotContextWithSnapshotAndAt(t *testing.T) {
+	is := is.New(t)
+	httpRec := httptest.NewRecorder()
+
+	h := SnapshotContext(nil, false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r, _ := http.NewRequest("GET", "/api/c4/context?snapshot=1&at=2024-01-31", nil)
+	h.ServeHTTP(httpRec, r)
+
+	is.Equal(httpRec.Result().StatusCode, http.StatusBadRequest)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+	"github.io/remast/c4stage/shared"
 	"github.io/remast/c4stage/shared/paged"
 )
 
@@ -18,35 +19,26 @@ var dependsOnWhitelist = map[string]string{
 	"system":    "System",
 	"component": "Component",
 	"api":       "API",
+	"resource":  "Component",
 }
 
 type CatalogRepositoryNeo4j struct {
 	Driver neo4j.DriverWithContext
 }
 
+// Reset deletes all entities of the snapshot of the catalog the context is
+// scoped to, which by default is the live graph. Other snapshots and the
+// schema are kept.
 func (r *CatalogRepositoryNeo4j) Reset(ctx context.Context) error {
-	resetStatements := []string{
-		"MATCH (n) DETACH DELETE n",
-		"DROP CONSTRAINT system_name_idx IF EXISTS",
-		`
-		CREATE CONSTRAINT system_name_idx
-		FOR (s:System) REQUIRE s.name IS UNIQUE`,
-		"DROP CONSTRAINT component_name_idx IF EXISTS",
+	_, err := neo4j.ExecuteQuery(ctx, r.Driver,
 		`
-		CREATE CONSTRAINT component_name_idx
-		FOR (c:Component) REQUIRE c.name IS UNIQUE`,
-	}
-
-	for _, resetStatement := range resetStatements {
-		_, err := neo4j.ExecuteQuery(ctx, r.Driver,
-			resetStatement,
-			map[string]any{}, neo4j.EagerResultTransformer)
-		if err != nil {
-			return err
-		}
-
-	}
-	return nil
+		MATCH (n{snapshot: $snapshot})
+		DETACH DELETE n
+		`,
+		map[string]any{
+			"snapshot": shared.SnapshotFromContext(ctx),
+		}, neo4j.EagerResultTransformer)
+	return err
 }
 
 func (r *CatalogRepositoryNeo4j) FindSystems(
@@ -55,13 +47,14 @@ func (r *CatalogRepositoryNeo4j) FindSystems(
 ) ([]System, *paged.Page, error) {
 	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
 		`
-		MATCH (s:System)
+		MATCH (s:System{snapshot: $snapshot})
 		WHERE s.type <> "person" AND s.type <> "external"
 		RETURN s
 		LIMIT $limit
 		`,
 		map[string]any{
-			"limit": pageParams.Size,
+			"snapshot": shared.SnapshotFromContext(ctx),
+			"limit":    pageParams.Size,
 		}, neo4j.EagerResultTransformer)
 
 	if err != nil {
@@ -89,27 +82,64 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 	ctx context.Context,
 	entities []any,
 ) error {
+	snapshot := shared.SnapshotFromContext(ctx)
+
+	// subcomponents are created last as they may depend on each other
+	var subComponents []SubComponent
+
 	for _, entity := range entities {
 		var err error
 		switch e := entity.(type) {
+		case SubComponent:
+			subComponents = append(subComponents, e)
+		case DeploymentNode:
+			_, err = neo4j.ExecuteQuery(ctx, r.Driver,
+				`
+				MERGE (n:DeploymentNode { name: $name, snapshot: $snapshot })
+				SET n.title = $title
+				SET n.description = $description
+				SET n.type = $type
+				SET n.tags = $tags
+				SET n.deployments = $deployments
+				SET n.defined = true
+				RETURN n
+				`,
+				map[string]any{
+					"snapshot":    snapshot,
+					"name":        e.Name,
+					"title":       e.Title,
+					"description": e.Description,
+					"type":        e.Type,
+					"tags":        e.Tags,
+					"deployments": DeploymentsToProps(e.Deployments),
+				}, neo4j.EagerResultTransformer)
 		case System:
 			_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 				`
-				MERGE (s:System { name: $name })
-				ON MATCH
-					SET s.titleTmp = $title
-					SET s.title = $title
-					SET s.description = $description
-					SET s.type = $type
-					SET s.lifecycle = $lifecycle
+				MERGE (s:System { name: $name, snapshot: $snapshot })
+				SET s.titleTmp = $title
+				SET s.title = $title
+				SET s.description = $description
+				SET s.type = $type
+				SET s.lifecycle = $lifecycle
+				SET s.owner = $owner
+				SET s.domain = $domain
+				SET s.tags = $tags
+				SET s.dependsOn = $dependsOn
+				SET s.defined = true
 				RETURN s
 				`,
 				map[string]any{
+					"snapshot":    snapshot,
 					"name":        e.Name,
 					"title":       e.Title,
 					"description": e.Description,
 					"type":        e.Type,
 					"lifecycle":   e.Lifecycle,
+					"owner":       e.Owner,
+					"domain":      e.Domain,
+					"tags":        e.Tags,
+					"dependsOn":   e.DependsOn,
 				}, neo4j.EagerResultTransformer)
 
 			if len(e.DependsOn) > 0 {
@@ -120,9 +150,10 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 					}
 
 					_, err = neo4j.ExecuteQuery(ctx, r.Driver,
-						fmt.Sprintf("MERGE (n:%s { name: $name }) RETURN n", dependsOnKind),
+						fmt.Sprintf("MERGE (n:%s { name: $name, snapshot: $snapshot }) RETURN n", dependsOnKind),
 						map[string]any{
-							"name": dependsOnName,
+							"snapshot": snapshot,
+							"name":     dependsOnName,
 						}, neo4j.EagerResultTransformer)
 					if err != nil {
 						return err
@@ -130,10 +161,11 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 
 					_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 						fmt.Sprintf(`
-									MATCH (c:System{ name: $name})
-									MATCH (d:%s{ name: $dependsOnName })
+									MATCH (c:System{ name: $name, snapshot: $snapshot })
+									MATCH (d:%s{ name: $dependsOnName, snapshot: $snapshot })
 									MERGE (c)-[:DEPENDS_ON]->(d)`, dependsOnKind),
 						map[string]any{
+							"snapshot":      snapshot,
 							"name":          e.Name,
 							"dependsOnName": dependsOnName,
 						}, neo4j.EagerResultTransformer)
@@ -145,54 +177,74 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 		case Container:
 			_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 				`
-				MERGE (c:Component { name: $name })
-				ON MATCH
-					SET c.titleTmp = $title
-					SET c.title = $title
-					SET c.description = $description
-					SET c.system = $system
-					SET c.type = $type
-					SET c.lifecycle = $lifecycle
-					SET c.tags = $tags
+				MERGE (c:Component { name: $name, snapshot: $snapshot })
+				SET c.titleTmp = $title
+				SET c.title = $title
+				SET c.description = $description
+				SET c.system = $system
+				SET c.type = $type
+				SET c.lifecycle = $lifecycle
+				SET c.owner = $owner
+				SET c.domain = $domain
+				SET c.tags = $tags
+				SET c.kind = $kind
+				SET c.dependsOn = $dependsOn
+				SET c.consumesApis = $consumesApis
+				SET c.providesApis = $providesApis
+				SET c.deployments = $deployments
+				SET c.usages = $usages
+				SET c.defined = true
 				RETURN c
 				`,
 				map[string]any{
-					"name":        e.Name,
-					"title":       e.Title,
-					"system":      e.System,
-					"description": e.Description,
-					"type":        e.Type,
-					"lifecycle":   e.Lifecycle,
-					"tags":        e.Tags,
+					"snapshot":     snapshot,
+					"name":         e.Name,
+					"title":        e.Title,
+					"system":       e.System,
+					"description":  e.Description,
+					"type":         e.Type,
+					"lifecycle":    e.Lifecycle,
+					"owner":        e.Owner,
+					"domain":       e.Domain,
+					"tags":         e.Tags,
+					"kind":         e.Kind,
+					"dependsOn":    e.DependsOn,
+					"consumesApis": e.ConsumesAPIs,
+					"providesApis": e.ProvidesAPIs,
+					"deployments":  DeploymentsToProps(e.Deployments),
+					"usages":       UsagesToProps(e.Usages),
 				}, neo4j.EagerResultTransformer)
 			if err != nil {
 				return err
 			}
 			if e.System != "" {
 				_, err = neo4j.ExecuteQuery(ctx, r.Driver,
-					"MERGE (n:System { name: $name }) RETURN n",
+					"MERGE (n:System { name: $name, snapshot: $snapshot }) RETURN n",
 					map[string]any{
-						"name": e.System,
+						"snapshot": snapshot,
+						"name":     e.System,
 					}, neo4j.EagerResultTransformer)
 				if err != nil {
 					return err
 				}
 
 				_, err = neo4j.ExecuteQuery(ctx, r.Driver,
-					"MERGE (c:Component { name: $name }) RETURN c",
+					"MERGE (c:Component { name: $name, snapshot: $snapshot }) RETURN c",
 					map[string]any{
-						"name": e.Name,
+						"snapshot": snapshot,
+						"name":     e.Name,
 					}, neo4j.EagerResultTransformer)
 				if err != nil {
 					return err
 				}
 				_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 					`
-					MATCH (s:System{ name: $system })
-					MATCH (c:Component{ name: $componentName })
+					MATCH (s:System{ name: $system, snapshot: $snapshot })
+					MATCH (c:Component{ name: $componentName, snapshot: $snapshot })
 					MERGE (s)-[:CONTAINS]->(c)
 					`,
 					map[string]any{
+						"snapshot":      snapshot,
 						"componentName": e.Name,
 						"system":        e.System,
 					}, neo4j.EagerResultTransformer)
@@ -203,31 +255,39 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 			if len(e.ConsumesAPIs) > 0 {
 				for _, consumedAPI := range e.ConsumesAPIs {
 					_, err = neo4j.ExecuteQuery(ctx, r.Driver,
-						"MERGE (n:Component { name: $name }) RETURN n",
+						"MERGE (n:Component { name: $name, snapshot: $snapshot }) RETURN n",
 						map[string]any{
-							"name": e.Name,
+							"snapshot": snapshot,
+							"name":     e.Name,
 						}, neo4j.EagerResultTransformer)
 					if err != nil {
 						return err
 					}
 
 					_, err = neo4j.ExecuteQuery(ctx, r.Driver,
-						"MERGE (a:API { name: $name }) RETURN a",
+						"MERGE (a:API { name: $name, snapshot: $snapshot }) RETURN a",
 						map[string]any{
-							"name": consumedAPI,
+							"snapshot": snapshot,
+							"name":     consumedAPI,
 						}, neo4j.EagerResultTransformer)
 					if err != nil {
 						return err
 					}
 
+					usage := e.Usages[consumedAPI]
 					_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 						`
-						MATCH (c:Component{ name: $name})
-						MATCH (a:API{ name: $apiName })
-						MERGE (c)-[:CONSUMES]->(a)`,
+						MATCH (c:Component{ name: $name, snapshot: $snapshot })
+						MATCH (a:API{ name: $apiName, snapshot: $snapshot })
+						MERGE (c)-[u:CONSUMES]->(a)
+						SET u.description = $description
+						SET u.technology = $technology`,
 						map[string]any{
-							"name":    e.Name,
-							"apiName": consumedAPI,
+							"snapshot":    snapshot,
+							"name":        e.Name,
+							"apiName":     consumedAPI,
+							"description": usage.Description,
+							"technology":  usage.Technology,
 						}, neo4j.EagerResultTransformer)
 					if err != nil {
 						return err
@@ -236,6 +296,22 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 
 			}
 
+			for _, providedAPI := range e.ProvidesAPIs {
+				_, err = neo4j.ExecuteQuery(ctx, r.Driver,
+					`
+					MERGE (c:Component { name: $name, snapshot: $snapshot })
+					MERGE (a:API { name: $apiName, snapshot: $snapshot })
+					MERGE (c)-[:PROVIDES]->(a)`,
+					map[string]any{
+						"snapshot": snapshot,
+						"name":     e.Name,
+						"apiName":  providedAPI,
+					}, neo4j.EagerResultTransformer)
+				if err != nil {
+					return err
+				}
+			}
+
 			if len(e.DependsOn) > 0 {
 				for _, dependsOn := range e.DependsOn {
 					dependsOnKind, dependsOnName, err := parseDependsOn(dependsOn)
@@ -245,9 +321,10 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 					}
 
 					_, err = neo4j.ExecuteQuery(ctx, r.Driver,
-						fmt.Sprintf("MERGE (n:%s { name: $name }) RETURN n", dependsOnKind),
+						fmt.Sprintf("MERGE (n:%s { name: $name, snapshot: $snapshot }) RETURN n", dependsOnKind),
 						map[string]any{
-							"name": dependsOnName,
+							"snapshot": snapshot,
+							"name":     dependsOnName,
 						}, neo4j.EagerResultTransformer)
 					if err != nil {
 						return err
@@ -255,10 +332,11 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 
 					_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 						fmt.Sprintf(`
-								MATCH (c:Component{ name: $name})
-								MATCH (d:%s{ name: $dependsOnName })
+								MATCH (c:Component{ name: $name, snapshot: $snapshot })
+								MATCH (d:%s{ name: $dependsOnName, snapshot: $snapshot })
 								MERGE (c)-[:DEPENDS_ON]->(d)`, dependsOnKind),
 						map[string]any{
+							"snapshot":      snapshot,
 							"name":          e.Name,
 							"dependsOnName": dependsOnName,
 						}, neo4j.EagerResultTransformer)
@@ -271,34 +349,51 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 		case API:
 			_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 				`
-				MERGE (a:API { name: $name })
-				ON MATCH
-					SET a.system = $system
-					SET a.lifecycle = $lifecycle
+				MERGE (a:API { name: $name, snapshot: $snapshot })
+				SET a.title = $title
+				SET a.description = $description
+				SET a.type = $type
+				SET a.system = $system
+				SET a.lifecycle = $lifecycle
+				SET a.owner = $owner
+				SET a.domain = $domain
+				SET a.tags = $tags
+				SET a.definition = $definition
+				SET a.defined = true
 				RETURN a
 				`,
 				map[string]any{
-					"name":      e.Name,
-					"system":    e.System,
-					"lifecycle": e.Lifecycle,
+					"snapshot":    snapshot,
+					"name":        e.Name,
+					"title":       e.Title,
+					"description": e.Description,
+					"type":        e.Type,
+					"system":      e.System,
+					"lifecycle":   e.Lifecycle,
+					"owner":       e.Owner,
+					"domain":      e.Domain,
+					"tags":        e.Tags,
+					"definition":  e.Definition,
 				}, neo4j.EagerResultTransformer)
 			if err != nil {
 				return err
 			}
 			if e.System != "" {
 				_, err = neo4j.ExecuteQuery(ctx, r.Driver,
-					"MERGE (n:System { name: $name }) RETURN n",
+					"MERGE (n:System { name: $name, snapshot: $snapshot }) RETURN n",
 					map[string]any{
-						"name": e.System,
+						"snapshot": snapshot,
+						"name":     e.System,
 					}, neo4j.EagerResultTransformer)
 				if err != nil {
 					return err
 				}
 
 				_, err = neo4j.ExecuteQuery(ctx, r.Driver,
-					"MERGE (a:API { name: $name }) RETURN a",
+					"MERGE (a:API { name: $name, snapshot: $snapshot }) RETURN a",
 					map[string]any{
-						"name": e.Name,
+						"snapshot": snapshot,
+						"name":     e.Name,
 					}, neo4j.EagerResultTransformer)
 				if err != nil {
 					return err
@@ -306,12 +401,13 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 
 				_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 					`
-					MATCH (s:System{ name: $system})
-					MATCH (a:API{ name: $apiName })
+					MATCH (s:System{ name: $system, snapshot: $snapshot })
+					MATCH (a:API{ name: $apiName, snapshot: $snapshot })
 					MERGE (s)-[:PROVIDES]->(a)`,
 					map[string]any{
-						"apiName": e.Name,
-						"system":  e.System,
+						"snapshot": snapshot,
+						"apiName":  e.Name,
+						"system":   e.System,
 					}, neo4j.EagerResultTransformer)
 				if err != nil {
 					return err
@@ -324,18 +420,35 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 		}
 	}
 
-	// Link API with Systems and Containers
-	_, err := neo4j.ExecuteQuery(ctx, r.Driver,
+	err := r.createSubComponents(ctx, subComponents)
+	if err != nil {
+		return err
+	}
+
+	// Link API with Systems and Containers, describing the use by a system
+	// with the first description and technology of the uses by its
+	// containers ordered by name
+	_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 		`
-		MATCH (c:Component)-[:CONSUMES]-(a:API)
-		MATCH (sourceSystem:System{name: c.system})
-		MATCH (targetSystem:System{name: a.system})
+		MATCH (c:Component{snapshot: $snapshot})-[u:CONSUMES]-(a:API)
+		MATCH (sourceSystem:System{name: c.system, snapshot: $snapshot})
+		MATCH (targetSystem:System{name: a.system, snapshot: $snapshot})
 		WHERE c.system <> a.system
-		CREATE (sourceSystem)-[:DEPENDS_ON{apiName: a.name}]->(targetSystem)
-		CREATE (c)-[:DEPENDS_ON{apiName: a.name}]->(targetSystem)
-		RETURN c, a, sourceSystem
+		MERGE (c)-[containerDep:DEPENDS_ON{apiName: a.name}]->(targetSystem)
+		SET containerDep.apiType = a.type
+		SET containerDep.description = u.description
+		SET containerDep.technology = u.technology
+		WITH sourceSystem, targetSystem, a, u ORDER BY c.name
+		WITH sourceSystem, targetSystem, a, collect(u) AS usages
+		MERGE (sourceSystem)-[systemDep:DEPENDS_ON{apiName: a.name}]->(targetSystem)
+		SET systemDep.apiType = a.type
+		SET systemDep.description = head([usage IN usages WHERE usage.description IS NOT NULL]).description
+		SET systemDep.technology = head([usage IN usages WHERE usage.technology IS NOT NULL]).technology
+		RETURN a, sourceSystem
 		`,
-		map[string]any{}, neo4j.EagerResultTransformer)
+		map[string]any{
+			"snapshot": snapshot,
+		}, neo4j.EagerResultTransformer)
 	if err != nil {
 		return err
 	}
@@ -343,16 +456,18 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 	// Create relations from Components to Systems
 	_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 		`
-		MATCH (sourceContainer:Component)-[:DEPENDS_ON]->(targetContainer:Component)
-		MATCH (sourceSystem:System{name: sourceContainer.system})
-		MATCH (targetSystem:System{name: targetContainer.system})
+		MATCH (sourceContainer:Component{snapshot: $snapshot})-[:DEPENDS_ON]->(targetContainer:Component)
+		MATCH (sourceSystem:System{name: sourceContainer.system, snapshot: $snapshot})
+		MATCH (targetSystem:System{name: targetContainer.system, snapshot: $snapshot})
 		WHERE sourceContainer.system <> targetContainer.system
 		MERGE (sourceSystem)-[:DEPENDS_ON]->(targetContainer)
 		MERGE (sourceContainer)-[:DEPENDS_ON]->(targetSystem)
 		MERGE (sourceSystem)-[:DEPENDS_ON]->(targetSystem)
 		RETURN targetContainer, sourceSystem
 			`,
-		map[string]any{}, neo4j.EagerResultTransformer)
+		map[string]any{
+			"snapshot": snapshot,
+		}, neo4j.EagerResultTransformer)
 	if err != nil {
 		return err
 	}
@@ -360,44 +475,268 @@ func (r *CatalogRepositoryNeo4j) CreateAll(
 	// Link System and Container with External Systems
 	_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 		`
-		MATCH (sourceContainer:Component)-[:DEPENDS_ON]->(targetSystem:System)
-		MATCH (sourceSystem:System{name: sourceContainer.system})
+		MATCH (sourceContainer:Component{snapshot: $snapshot})-[:DEPENDS_ON]->(targetSystem:System)
+		MATCH (sourceSystem:System{name: sourceContainer.system, snapshot: $snapshot})
 		WHERE sourceContainer.system <> targetSystem.name
 		MERGE (sourceSystem)-[:DEPENDS_ON]->(targetSystem)
 		RETURN sourceContainer, targetSystem
 			`,
-		map[string]any{}, neo4j.EagerResultTransformer)
+		map[string]any{
+			"snapshot": snapshot,
+		}, neo4j.EagerResultTransformer)
 	if err != nil {
 		return err
 	}
 	_, err = neo4j.ExecuteQuery(ctx, r.Driver,
 		`
-			MATCH (sourceContainer:Component)<-[:DEPENDS_ON]-(targetSystem:System)
-			MATCH (sourceSystem:System{name: sourceContainer.system})
+			MATCH (sourceContainer:Component{snapshot: $snapshot})<-[:DEPENDS_ON]-(targetSystem:System)
+			MATCH (sourceSystem:System{name: sourceContainer.system, snapshot: $snapshot})
 			WHERE sourceContainer.system <> targetSystem.name
 			MERGE (sourceSystem)<-[:DEPENDS_ON]-(targetSystem)
 			RETURN sourceContainer, targetSystem
 				`,
-		map[string]any{}, neo4j.EagerResultTransformer)
+		map[string]any{
+			"snapshot": snapshot,
+		}, neo4j.EagerResultTransformer)
+
+	return err
+}
+
+func (r *CatalogRepositoryNeo4j) FindLandscape(
+	ctx context.Context,
+) (*Landscape, error) {
+	snapshot := shared.SnapshotFromContext(ctx)
+
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (n:System|Component|SubComponent|API|DeploymentNode{snapshot: $snapshot})
+		RETURN n
+		ORDER BY n.name
+		`,
+		map[string]any{
+			"snapshot": snapshot,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return nil, err
+	}
+
+	landscape := &Landscape{}
+
+	for _, record := range result.Records {
+		node, _, err := neo4j.GetRecordValue[dbtype.Node](record, "n")
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.Contains(node.Labels, "System") {
+			landscape.Systems = append(landscape.Systems, *readSystem(node))
+		} else if slices.Contains(node.Labels, "Component") {
+			landscape.Containers = append(landscape.Containers, *readContainer(node))
+		} else if slices.Contains(node.Labels, "SubComponent") {
+			landscape.SubComponents = append(landscape.SubComponents, *readSubComponent(node))
+		} else if slices.Contains(node.Labels, "API") {
+			landscape.APIs = append(landscape.APIs, *readAPI(node))
+		} else if slices.Contains(node.Labels, "DeploymentNode") {
+			landscape.DeploymentNodes = append(landscape.DeploymentNodes, *readDeploymentNode(node))
+		}
+	}
+
+	result, err = neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (s:System|Component|API{snapshot: $snapshot})-[r]->(t:System|Component|API)
+		RETURN s, r, t
+		ORDER BY s.name, t.name
+		`,
+		map[string]any{
+			"snapshot": snapshot,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range result.Records {
+		source, _, err := neo4j.GetRecordValue[dbtype.Node](record, "s")
+		if err != nil {
+			return nil, err
+		}
+		relationship, _, err := neo4j.GetRecordValue[dbtype.Relationship](record, "r")
+		if err != nil {
+			return nil, err
+		}
+		target, _, err := neo4j.GetRecordValue[dbtype.Node](record, "t")
+		if err != nil {
+			return nil, err
+		}
+
+		relation := Relation{
+			Source: readRef(source),
+			Target: readRef(target),
+			Type:   relationship.Type,
+		}
+		if !slices.Contains(landscape.Relations, relation) {
+			landscape.Relations = append(landscape.Relations, relation)
+		}
+	}
+
+	return landscape, nil
+}
+
+// MarkViolations marks all given relations with the names of the rules
+// they violate and removes the marks of all other relations.
+func (r *CatalogRepositoryNeo4j) MarkViolations(
+	ctx context.Context,
+	violatedRules map[Relation][]string,
+) error {
+	snapshot := shared.SnapshotFromContext(ctx)
+
+	_, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (:System|Component|API{snapshot: $snapshot})-[r]->()
+		WHERE r.violations IS NOT NULL
+		REMOVE r.violations
+		`,
+		map[string]any{
+			"snapshot": snapshot,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return err
+	}
+
+	var violations []map[string]any
+	for relation, rules := range violatedRules {
+		sourceLabel, sourceName := ParseRef(relation.Source)
+		targetLabel, targetName := ParseRef(relation.Target)
+		violations = append(violations, map[string]any{
+			"sourceLabel": sourceLabel,
+			"sourceName":  sourceName,
+			"targetLabel": targetLabel,
+			"targetName":  targetName,
+			"type":        relation.Type,
+			"rules":       rules,
+		})
+	}
+
+	_, err = neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		UNWIND $violations AS v
+		MATCH (s:System|Component|API{name: v.sourceName, snapshot: $snapshot})-[r]->(t:System|Component|API{name: v.targetName})
+		WHERE v.sourceLabel IN labels(s) AND v.targetLabel IN labels(t) AND type(r) = v.type
+		SET r.violations = v.rules
+		`,
+		map[string]any{
+			"snapshot":   snapshot,
+			"violations": violations,
+		}, neo4j.EagerResultTransformer)
 
 	return err
 }
 
 func readSystem(node dbtype.Node) *System {
 	system := &System{}
-	system.EntityEnvelope = EntityEnvelope{
+	system.EntityEnvelope = readEntityEnvelope(node, "System")
+	system.DependsOn = readProps(node, "dependsOn")
+
+	return system
+}
+
+func readContainer(node dbtype.Node) *Container {
+	container := &Container{
+		EntityEnvelope: readEntityEnvelope(node, "Component"),
+		System:         readProp(node, "system"),
+		ConsumesAPIs:   readProps(node, "consumesApis"),
+		ProvidesAPIs:   readProps(node, "providesApis"),
+		DependsOn:      readProps(node, "dependsOn"),
+		Deployments:    DeploymentsFromProps(readProps(node, "deployments")),
+		Usages:         UsagesFromProps(readProps(node, "usages")),
+	}
+	if kind := readProp(node, "kind"); kind != "" {
+		container.Kind = kind
+	}
+
+	return container
+}
+
+func readAPI(node dbtype.Node) *API {
+	api := &API{
+		EntityEnvelope: readEntityEnvelope(node, "API"),
+		System:         readProp(node, "system"),
+		Definition:     readProp(node, "definition"),
+	}
+
+	return api
+}
+
+func readSubComponent(node dbtype.Node) *SubComponent {
+	subComponent := &SubComponent{
+		EntityEnvelope: readEntityEnvelope(node, "Component"),
+		Container:      readProp(node, "container"),
+		DependsOn:      readProps(node, "dependsOn"),
+	}
+
+	return subComponent
+}
+
+func readDeploymentNode(node dbtype.Node) *DeploymentNode {
+	deploymentNode := &DeploymentNode{
+		EntityEnvelope: readEntityEnvelope(node, "Resource"),
+		Deployments:    DeploymentsFromProps(readProps(node, "deployments")),
+	}
+
+	return deploymentNode
+}
+
+func readEntityEnvelope(node dbtype.Node, kind string) EntityEnvelope {
+	envelope := EntityEnvelope{
 		ID:          node.ElementId,
-		Name:        fmt.Sprintf("%v", node.Props["name"]),
-		Title:       fmt.Sprintf("%v", node.Props["title"]),
-		Description: fmt.Sprintf("%v", node.Props["description"]),
-		Lifecycle:   fmt.Sprintf("%v", node.Props["lifecycle"]),
+		Name:        readProp(node, "name"),
+		Title:       readProp(node, "title"),
+		Description: readProp(node, "description"),
+		Kind:        kind,
+		Type:        readProp(node, "type"),
+		Lifecycle:   readProp(node, "lifecycle"),
+		Owner:       readProp(node, "owner"),
+		Domain:      readProp(node, "domain"),
+		Defined:     node.Props["defined"] == true,
 	}
 
-	if system.Title == "" {
-		system.Title = system.Name
+	envelope.Tags = readProps(node, "tags")
+
+	if envelope.Title == "" {
+		envelope.Title = envelope.Name
 	}
 
-	return system
+	return envelope
+}
+
+// readProps reads a list property of a node as strings.
+func readProps(node dbtype.Node, key string) []string {
+	var values []string
+	if valuesRaw, ok := node.Props[key].([]any); ok {
+		for _, valueRaw := range valuesRaw {
+			values = append(values, fmt.Sprintf("%v", valueRaw))
+		}
+	}
+	return values
+}
+
+// readProp reads a property of a node as string, missing properties are
+// read as empty string.
+func readProp(node dbtype.Node, key string) string {
+	value, ok := node.Props[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func readRef(node dbtype.Node) string {
+	name := readProp(node, "name")
+	for _, label := range node.Labels {
+		if _, ok := refKinds[label]; ok {
+			return EntityRef(label, name)
+		}
+	}
+	return EntityRef(node.Labels[0], name)
 }
 
 func parseDependsOn(dependsOn string) (string, string, error) {
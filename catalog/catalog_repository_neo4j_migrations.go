@@ -0,0 +1,187 @@
+package catalog
+
+import (
+	"context"
+	"log"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const schemaVersionID = "c4stage"
+
+type migration struct {
+	version     int
+	description string
+	statements  []string
+}
+
+// migrations holds the versioned schema of the graph. Migrations are applied
+// in order and must never be changed once released, add a new one instead.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "unique names of systems and components",
+		statements: []string{
+			`
+			CREATE CONSTRAINT system_name_idx IF NOT EXISTS
+			FOR (s:System) REQUIRE s.name IS UNIQUE`,
+			`
+			CREATE CONSTRAINT component_name_idx IF NOT EXISTS
+			FOR (c:Component) REQUIRE c.name IS UNIQUE`,
+		},
+	},
+	{
+		version:     2,
+		description: "unique names of apis and index on system of components",
+		statements: []string{
+			`
+			CREATE CONSTRAINT api_name_idx IF NOT EXISTS
+			FOR (a:API) REQUIRE a.name IS UNIQUE`,
+			`
+			CREATE INDEX component_system_idx IF NOT EXISTS
+			FOR (c:Component) ON (c.system)`,
+		},
+	},
+	{
+		version:     3,
+		description: "scope entities to snapshots of the catalog",
+		statements: []string{
+			`
+			MATCH (n)
+			WHERE (n:System OR n:Component OR n:API) AND n.snapshot IS NULL
+			SET n.snapshot = "live"`,
+			"DROP CONSTRAINT system_name_idx IF EXISTS",
+			"DROP CONSTRAINT component_name_idx IF EXISTS",
+			"DROP CONSTRAINT api_name_idx IF EXISTS",
+			`
+			CREATE CONSTRAINT system_name_snapshot_idx IF NOT EXISTS
+			FOR (s:System) REQUIRE (s.name, s.snapshot) IS UNIQUE`,
+			`
+			CREATE CONSTRAINT component_name_snapshot_idx IF NOT EXISTS
+			FOR (c:Component) REQUIRE (c.name, c.snapshot) IS UNIQUE`,
+			`
+			CREATE CONSTRAINT api_name_snapshot_idx IF NOT EXISTS
+			FOR (a:API) REQUIRE (a.name, a.snapshot) IS UNIQUE`,
+			`
+			CREATE INDEX system_snapshot_idx IF NOT EXISTS
+			FOR (s:System) ON (s.snapshot)`,
+			`
+			CREATE INDEX component_snapshot_idx IF NOT EXISTS
+			FOR (c:Component) ON (c.snapshot)`,
+			`
+			CREATE INDEX api_snapshot_idx IF NOT EXISTS
+			FOR (a:API) ON (a.snapshot)`,
+			`
+			CREATE CONSTRAINT snapshot_id_idx IF NOT EXISTS
+			FOR (s:Snapshot) REQUIRE s.id IS UNIQUE`,
+			`
+			CREATE INDEX snapshot_created_at_idx IF NOT EXISTS
+			FOR (s:Snapshot) ON (s.createdAt)`,
+		},
+	},
+	{
+		version:     4,
+		description: "mark entities defined in the catalog",
+		statements: []string{
+			`
+			MATCH (n:System|Component|API)
+			WHERE n.defined IS NULL AND n.lifecycle IS NOT NULL
+			SET n.defined = true`,
+		},
+	},
+	{
+		version:     5,
+		description: "unique names of subcomponents",
+		statements: []string{
+			`
+			CREATE CONSTRAINT subcomponent_name_snapshot_idx IF NOT EXISTS
+			FOR (c:SubComponent) REQUIRE (c.name, c.snapshot) IS UNIQUE`,
+			`
+			CREATE INDEX subcomponent_container_idx IF NOT EXISTS
+			FOR (c:SubComponent) ON (c.container)`,
+		},
+	},
+	{
+		version:     6,
+		description: "unique names of deployment nodes",
+		statements: []string{
+			`
+			CREATE CONSTRAINT deployment_node_name_snapshot_idx IF NOT EXISTS
+			FOR (n:DeploymentNode) REQUIRE (n.name, n.snapshot) IS UNIQUE`,
+		},
+	},
+	{
+		version:     7,
+		description: "unique names of flows per system",
+		statements: []string{
+			`
+			CREATE CONSTRAINT flow_system_name_idx IF NOT EXISTS
+			FOR (f:Flow) REQUIRE (f.system, f.name) IS UNIQUE`,
+		},
+	},
+}
+
+// Migrate applies all schema migrations newer than the schema version
+// stored in the graph.
+func (r *CatalogRepositoryNeo4j) Migrate(ctx context.Context) error {
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MERGE (v:SchemaVersion { id: $id })
+		ON CREATE
+			SET v.version = 0
+		RETURN v.version AS version
+		`,
+		map[string]any{
+			"id": schemaVersionID,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return err
+	}
+
+	currentVersion := 0
+	if len(result.Records) > 0 {
+		version, _, err := neo4j.GetRecordValue[int64](result.Records[0], "version")
+		if err != nil {
+			return err
+		}
+		currentVersion = int(version)
+	}
+
+	for _, m := range pendingMigrations(currentVersion) {
+		log.Printf("Migrating schema to version %v (%v).", m.version, m.description)
+
+		for _, statement := range m.statements {
+			_, err := neo4j.ExecuteQuery(ctx, r.Driver,
+				statement,
+				map[string]any{}, neo4j.EagerResultTransformer)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = neo4j.ExecuteQuery(ctx, r.Driver,
+			`
+			MATCH (v:SchemaVersion { id: $id })
+			SET v.version = $version
+			`,
+			map[string]any{
+				"id":      schemaVersionID,
+				"version": m.version,
+			}, neo4j.EagerResultTransformer)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func pendingMigrations(currentVersion int) []migration {
+	var pending []migration
+	for _, m := range migrations {
+		if m.version > currentVersion {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
@@ -0,0 +1,40 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestMigrationsAreOrdered(t *testing.T) {
+	is := is.New(t)
+
+	for i := 1; i < len(migrations); i++ {
+		is.True(migrations[i-1].version < migrations[i].version)
+	}
+}
+
+func TestPendingMigrationsWithEmptySchema(t *testing.T) {
+	is := is.New(t)
+
+	pending := pendingMigrations(0)
+
+	is.Equal(len(pending), len(migrations))
+}
+
+func TestPendingMigrationsWithCurrentSchema(t *testing.T) {
+	is := is.New(t)
+
+	pending := pendingMigrations(migrations[len(migrations)-1].version)
+
+	is.Equal(len(pending), 0)
+}
+
+func TestPendingMigrationsWithOutdatedSchema(t *testing.T) {
+	is := is.New(t)
+
+	pending := pendingMigrations(1)
+
+	is.Equal(len(pending), len(migrations)-1)
+	is.Equal(pending[0].version, 2)
+}
@@ -0,0 +1,255 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+	"github.io/remast/c4stage/shared"
+)
+
+// snapshotLabels are the labels of all nodes copied into a snapshot.
+var snapshotLabels = []string{"System", "Component", "SubComponent", "API", "DeploymentNode"}
+
+// CreateSnapshot copies the live graph into a new snapshot and deletes the
+// oldest snapshots exceeding the retention. A retention below one keeps
+// all snapshots.
+func (r *CatalogRepositoryNeo4j) CreateSnapshot(
+	ctx context.Context,
+	source string,
+	retention int,
+) (*Snapshot, error) {
+	session := r.Driver.NewSession(ctx, neo4j.SessionConfig{})
+	defer session.Close(ctx)
+
+	snapshot, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		result, err := tx.Run(ctx,
+			`
+			CREATE (s:Snapshot { id: randomUUID(), createdAt: datetime(), source: $source })
+			RETURN s
+			`,
+			map[string]any{
+				"source": source,
+			})
+		if err != nil {
+			return nil, err
+		}
+		record, err := result.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		node, _, err := neo4j.GetRecordValue[dbtype.Node](record, "s")
+		if err != nil {
+			return nil, err
+		}
+		snapshot := readSnapshot(node)
+
+		// Copy all nodes of the live graph
+		copies := make(map[string]string)
+		for _, label := range snapshotLabels {
+			result, err := tx.Run(ctx,
+				fmt.Sprintf(`
+				MATCH (n:%s{snapshot: $live})
+				CREATE (c:%s)
+				SET c = properties(n), c.snapshot = $snapshot
+				RETURN elementId(n) AS origin, elementId(c) AS copy
+				`, label, label),
+				map[string]any{
+					"live":     shared.LiveSnapshot,
+					"snapshot": snapshot.ID,
+				})
+			if err != nil {
+				return nil, err
+			}
+			records, err := result.Collect(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, record := range records {
+				origin, _, _ := neo4j.GetRecordValue[string](record, "origin")
+				copied, _, _ := neo4j.GetRecordValue[string](record, "copy")
+				copies[origin] = copied
+			}
+		}
+
+		// Copy all relations between nodes of the live graph
+		result, err = tx.Run(ctx,
+			`
+			MATCH (source{snapshot: $live})-[r]->(target{snapshot: $live})
+			RETURN elementId(source) AS source, elementId(target) AS target, type(r) AS type, properties(r) AS properties
+			`,
+			map[string]any{
+				"live": shared.LiveSnapshot,
+			})
+		if err != nil {
+			return nil, err
+		}
+		records, err := result.Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		relationsByType := make(map[string][]map[string]any)
+		for _, record := range records {
+			source, _, _ := neo4j.GetRecordValue[string](record, "source")
+			target, _, _ := neo4j.GetRecordValue[string](record, "target")
+			relationType, _, _ := neo4j.GetRecordValue[string](record, "type")
+			properties, _, _ := neo4j.GetRecordValue[map[string]any](record, "properties")
+
+			sourceCopy, ok := copies[source]
+			if !ok {
+				continue
+			}
+			targetCopy, ok := copies[target]
+			if !ok {
+				continue
+			}
+
+			relationsByType[relationType] = append(relationsByType[relationType], map[string]any{
+				"source":     sourceCopy,
+				"target":     targetCopy,
+				"properties": properties,
+			})
+		}
+
+		for relationType, relations := range relationsByType {
+			_, err := tx.Run(ctx,
+				fmt.Sprintf(`
+				UNWIND $relations AS relation
+				MATCH (source) WHERE elementId(source) = relation.source
+				MATCH (target) WHERE elementId(target) = relation.target
+				CREATE (source)-[r:%s]->(target)
+				SET r = relation.properties
+				`, "`"+relationType+"`"),
+				map[string]any{
+					"relations": relations,
+				})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return snapshot, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if retention > 0 {
+		_, err = neo4j.ExecuteQuery(ctx, r.Driver,
+			`
+			MATCH (s:Snapshot)
+			WITH s ORDER BY s.createdAt DESC SKIP $retention
+			OPTIONAL MATCH (n{snapshot: s.id})
+			DETACH DELETE n, s
+			`,
+			map[string]any{
+				"retention": retention,
+			}, neo4j.EagerResultTransformer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return snapshot.(*Snapshot), nil
+}
+
+func (r *CatalogRepositoryNeo4j) FindSnapshots(
+	ctx context.Context,
+) ([]Snapshot, error) {
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (s:Snapshot)
+		RETURN s
+		ORDER BY s.createdAt DESC
+		`,
+		map[string]any{}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return []Snapshot{}, err
+	}
+
+	snapshots := []Snapshot{}
+	for _, record := range result.Records {
+		node, _, err := neo4j.GetRecordValue[dbtype.Node](record, "s")
+		if err != nil {
+			return []Snapshot{}, err
+		}
+		snapshots = append(snapshots, *readSnapshot(node))
+	}
+
+	return snapshots, nil
+}
+
+// FindSnapshot finds the snapshot with the given id, returns nil if
+// there is none.
+func (r *CatalogRepositoryNeo4j) FindSnapshot(
+	ctx context.Context,
+	id string,
+) (*Snapshot, error) {
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (s:Snapshot{id: $id})
+		RETURN s
+		`,
+		map[string]any{
+			"id": id,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return nil, err
+	}
+
+	return readFirstSnapshot(result.Records)
+}
+
+// FindSnapshotAt finds the latest snapshot created at or before the given
+// time, returns nil if there is none.
+func (r *CatalogRepositoryNeo4j) FindSnapshotAt(
+	ctx context.Context,
+	at time.Time,
+) (*Snapshot, error) {
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (s:Snapshot)
+		WHERE s.createdAt <= $at
+		RETURN s
+		ORDER BY s.createdAt DESC
+		LIMIT 1
+		`,
+		map[string]any{
+			"at": at,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return nil, err
+	}
+
+	return readFirstSnapshot(result.Records)
+}
+
+func readFirstSnapshot(records []*neo4j.Record) (*Snapshot, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	node, _, err := neo4j.GetRecordValue[dbtype.Node](records[0], "s")
+	if err != nil {
+		return nil, err
+	}
+
+	return readSnapshot(node), nil
+}
+
+func readSnapshot(node dbtype.Node) *Snapshot {
+	snapshot := &Snapshot{
+		ID:     fmt.Sprintf("%v", node.Props["id"]),
+		Source: fmt.Sprintf("%v", node.Props["source"]),
+	}
+
+	createdAt, ok := node.Props["createdAt"].(time.Time)
+	if ok {
+		snapshot.CreatedAt = createdAt
+	}
+
+	return snapshot
+}
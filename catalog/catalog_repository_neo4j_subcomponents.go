@@ -0,0 +1,119 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.io/remast/c4stage/shared"
+)
+
+// createSubComponents creates the subcomponents within their containers.
+// Dependencies on components are resolved to subcomponents first, so all
+// subcomponents are created before their dependencies.
+func (r *CatalogRepositoryNeo4j) createSubComponents(
+	ctx context.Context,
+	subComponents []SubComponent,
+) error {
+	snapshot := shared.SnapshotFromContext(ctx)
+
+	for _, e := range subComponents {
+		_, err := neo4j.ExecuteQuery(ctx, r.Driver,
+			`
+			MERGE (c:Component { name: $container, snapshot: $snapshot })
+			MERGE (sc:SubComponent { name: $name, snapshot: $snapshot })
+			SET sc.title = $title
+			SET sc.description = $description
+			SET sc.container = $container
+			SET sc.type = $type
+			SET sc.lifecycle = $lifecycle
+			SET sc.tags = $tags
+			SET sc.dependsOn = $dependsOn
+			SET sc.defined = true
+			MERGE (c)-[:CONTAINS]->(sc)
+			`,
+			map[string]any{
+				"snapshot":    snapshot,
+				"name":        e.Name,
+				"title":       e.Title,
+				"description": e.Description,
+				"container":   e.Container,
+				"type":        e.Type,
+				"lifecycle":   e.Lifecycle,
+				"tags":        e.Tags,
+				"dependsOn":   e.DependsOn,
+			}, neo4j.EagerResultTransformer)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, e := range subComponents {
+		for _, dependsOn := range e.DependsOn {
+			dependsOnKind, dependsOnName, err := parseDependsOn(dependsOn)
+			if err != nil {
+				log.Printf("Ignoring dependsOn: %v", dependsOn)
+				continue
+			}
+
+			if dependsOnKind == "Component" {
+				isSubComponent, err := r.existsSubComponent(ctx, dependsOnName)
+				if err != nil {
+					return err
+				}
+				if isSubComponent {
+					dependsOnKind = "SubComponent"
+				}
+			}
+
+			_, err = neo4j.ExecuteQuery(ctx, r.Driver,
+				fmt.Sprintf(`
+				MATCH (sc:SubComponent{ name: $name, snapshot: $snapshot })
+				MERGE (d:%s{ name: $dependsOnName, snapshot: $snapshot })
+				MERGE (sc)-[:DEPENDS_ON]->(d)`, dependsOnKind),
+				map[string]any{
+					"snapshot":      snapshot,
+					"name":          e.Name,
+					"dependsOnName": dependsOnName,
+				}, neo4j.EagerResultTransformer)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Link containers with the dependencies of their subcomponents
+	_, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (c:Component{snapshot: $snapshot})-[:CONTAINS]->(:SubComponent)-[:DEPENDS_ON]->(d:Component|System)
+		WHERE c <> d
+		MERGE (c)-[:DEPENDS_ON]->(d)
+		`,
+		map[string]any{
+			"snapshot": snapshot,
+		}, neo4j.EagerResultTransformer)
+
+	return err
+}
+
+func (r *CatalogRepositoryNeo4j) existsSubComponent(ctx context.Context, name string) (bool, error) {
+	result, err := neo4j.ExecuteQuery(ctx, r.Driver,
+		`
+		MATCH (sc:SubComponent{ name: $name, snapshot: $snapshot })
+		RETURN count(sc) AS count
+		`,
+		map[string]any{
+			"snapshot": shared.SnapshotFromContext(ctx),
+			"name":     name,
+		}, neo4j.EagerResultTransformer)
+	if err != nil {
+		return false, err
+	}
+
+	count, _, err := neo4j.GetRecordValue[int64](result.Records[0], "count")
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -53,3 +53,53 @@ func TestParseDependsOnInvalid(t *testing.T) {
 
 	is.True(err != nil)
 }
+
+func TestParseRef(t *testing.T) {
+	is := is.New(t)
+
+	label, name := ParseRef("api:my-api")
+
+	is.Equal(label, "API")
+	is.Equal(name, "my-api")
+}
+
+func TestParseRefWithoutKind(t *testing.T) {
+	is := is.New(t)
+
+	label, name := ParseRef("my-component")
+
+	is.Equal(label, "Component")
+	is.Equal(name, "my-component")
+}
+
+func TestDeploymentsProps(t *testing.T) {
+	is := is.New(t)
+
+	deployments := map[string]string{
+		"production": "aws-prod/eks-cluster",
+		"staging":    "aws-staging/eks-cluster",
+	}
+
+	props := DeploymentsToProps(deployments)
+
+	is.Equal(props, []string{"production=aws-prod/eks-cluster", "staging=aws-staging/eks-cluster"})
+	is.Equal(DeploymentsFromProps(props), deployments)
+}
+
+func TestUsagesProps(t *testing.T) {
+	is := is.New(t)
+
+	usages := map[string]Usage{
+		"orders.v2-api": {Description: "places orders", Technology: "REST/JSON"},
+		"stock-api":     {Technology: "gRPC"},
+	}
+
+	props := UsagesToProps(usages)
+
+	is.Equal(props, []string{
+		"orders.v2-api.description=places orders",
+		"orders.v2-api.technology=REST/JSON",
+		"stock-api.technology=gRPC",
+	})
+	is.Equal(UsagesFromProps(props), usages)
+}
@@ -4,6 +4,7 @@ go 1.21
 
 require (
 	github.com/go-chi/chi/v5 v5.0.10
+	github.com/graphql-go/graphql v0.8.1
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/matryer/is v1.4.1
 	github.com/neo4j/neo4j-go-driver/v5 v5.15.0
@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/graphql-go/graphql"
+	"github.io/remast/c4stage/c4"
+	"github.io/remast/c4stage/catalog"
+	"github.io/remast/c4stage/shared"
+	"schneider.vip/problem"
+)
+
+type GraphController struct {
+	Config  *shared.Config
+	Catalog catalog.CatalogRepository
+	C4      c4.C4Repository
+}
+
+type queryModel struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+func (c *GraphController) RegisterProtected(router chi.Router) {
+}
+
+func (c *GraphController) RegisterOpen(router chi.Router) {
+	r := chi.NewRouter()
+	router.Mount("/graphql", r)
+
+	handleQuery := c.HandleQuery()
+	r.Get("/", handleQuery)
+	r.Post("/", handleQuery)
+}
+
+// HandleQuery executes a GraphQL query, either posted as JSON or given by
+// query parameter `query`. Queries exceeding the limits of depth and number
+// of fields are rejected.
+func (c *GraphController) HandleQuery() http.HandlerFunc {
+	schema, err := NewSchema()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		queryModel := queryModel{
+			Query: r.URL.Query().Get("query"),
+		}
+		if r.Method == http.MethodPost {
+			err := json.NewDecoder(r.Body).Decode(&queryModel)
+			if err != nil {
+				http.Error(w, problem.New(problem.Title("invalid query")).JSONString(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		err := checkQueryLimits(queryModel.Query)
+		if err != nil {
+			http.Error(w, problem.New(problem.Title("query too complex"), problem.Detail(err.Error())).JSONString(), http.StatusBadRequest)
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  queryModel.Query,
+			OperationName:  queryModel.OperationName,
+			VariableValues: queryModel.Variables,
+			Context:        r.Context(),
+			RootObject: (&Root{
+				Context: r.Context(),
+				Catalog: c.Catalog,
+				C4:      c.C4,
+			}).RootObject(),
+		})
+
+		shared.RenderJSON(w, result)
+	}
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+const (
+	// maxQueryDepth is the maximum nesting of fields of a query.
+	maxQueryDepth = 10
+	// maxQueryFields is the maximum number of fields of a query, counting
+	// the fields of fragments at every use.
+	maxQueryFields = 500
+)
+
+// checkQueryLimits checks the query against the maximum depth and number of
+// fields before it is executed, as every nested field resolves relations of
+// the whole landscape. Queries failing to parse pass, as their errors are
+// reported by the execution.
+func checkQueryLimits(query string) error {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		c := &queryCounter{fragments: fragments, spread: make(map[string]bool)}
+		err := c.count(operation.SelectionSet, 1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queryCounter counts the fields of an operation up to the limits.
+type queryCounter struct {
+	fragments map[string]*ast.FragmentDefinition
+	// spread are the fragments spread on the current path, to stop at
+	// cyclic fragments.
+	spread map[string]bool
+	fields int
+}
+
+func (c *queryCounter) count(selectionSet *ast.SelectionSet, depth int) error {
+	if selectionSet == nil {
+		return nil
+	}
+
+	for _, selection := range selectionSet.Selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if depth > maxQueryDepth {
+				return fmt.Errorf("query is nested deeper than %v fields", maxQueryDepth)
+			}
+			c.fields++
+			if c.fields > maxQueryFields {
+				return fmt.Errorf("query has more than %v fields", maxQueryFields)
+			}
+
+			err := c.count(s.SelectionSet, depth+1)
+			if err != nil {
+				return err
+			}
+		case *ast.InlineFragment:
+			err := c.count(s.SelectionSet, depth)
+			if err != nil {
+				return err
+			}
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			fragment, ok := c.fragments[name]
+			if !ok || c.spread[name] {
+				continue
+			}
+
+			c.spread[name] = true
+			err := c.count(fragment.SelectionSet, depth)
+			c.spread[name] = false
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
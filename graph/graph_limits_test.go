@@ -0,0 +1,38 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCheckQueryLimits(t *testing.T) {
+	is := is.New(t)
+
+	is.NoErr(checkQueryLimits(`{ systems { name containers { name } } }`))
+	is.NoErr(checkQueryLimits(`{ systems {`))
+
+	deep := strings.Repeat("{ systems ", maxQueryDepth+1) + strings.Repeat("}", maxQueryDepth+1)
+	is.True(checkQueryLimits(deep) != nil)
+}
+
+func TestCheckQueryLimitsWithFragments(t *testing.T) {
+	is := is.New(t)
+
+	// each fragment doubles the fields of the one it spreads
+	query := `{ systems { ...f0 } }
+	fragment f0 on System { a: name b: name }`
+	for i := 1; i < 9; i++ {
+		query += fmt.Sprintf(" fragment f%v on System { dependsOn { ...f%v } dependedOnBy { ...f%v } }", i, i-1, i-1)
+	}
+	query = strings.Replace(query, "...f0 } }", "...f8 } }", 1)
+
+	err := checkQueryLimits(query)
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "more than"))
+
+	cyclic := `{ systems { ...a } } fragment a on System { name ...a }`
+	is.NoErr(checkQueryLimits(cyclic))
+}
@@ -0,0 +1,600 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.io/remast/c4stage/analysis"
+	"github.io/remast/c4stage/c4"
+	"github.io/remast/c4stage/catalog"
+)
+
+const (
+	ViewContext   = "context"
+	ViewContainer = "container"
+)
+
+// Root is the root value of a query, it loads the landscape of the catalog
+// once per query.
+type Root struct {
+	Context     context.Context
+	Catalog     catalog.CatalogRepository
+	C4          c4.C4Repository
+	loadOnce    sync.Once
+	landscape   *catalog.Landscape
+	entityGraph *analysis.Graph
+	err         error
+}
+
+func (r *Root) load() (*catalog.Landscape, *analysis.Graph, error) {
+	r.loadOnce.Do(func() {
+		r.landscape, r.err = r.Catalog.FindLandscape(r.Context)
+		if r.err == nil {
+			r.entityGraph = analysis.EntityGraph(r.landscape)
+		}
+	})
+	return r.landscape, r.entityGraph, r.err
+}
+
+// RootObject wraps the root as root object of a query.
+func (r *Root) RootObject() map[string]any {
+	return map[string]any{
+		"root": r,
+	}
+}
+
+func rootOf(p graphql.ResolveParams) *Root {
+	return p.Info.RootValue.(map[string]any)["root"].(*Root)
+}
+
+// NewSchema creates the GraphQL schema over systems, containers, APIs,
+// relations and C4 diagram models of the catalog.
+func NewSchema() (graphql.Schema, error) {
+	var systemType, containerType, apiType *graphql.Object
+
+	filterArgs := graphql.FieldConfigArgument{
+		"name":      &graphql.ArgumentConfig{Type: graphql.String, Description: "Name or pattern like `orders-*`."},
+		"type":      &graphql.ArgumentConfig{Type: graphql.String},
+		"lifecycle": &graphql.ArgumentConfig{Type: graphql.String},
+		"tag":       &graphql.ArgumentConfig{Type: graphql.String},
+		"system":    &graphql.ArgumentConfig{Type: graphql.String, Description: "Name or pattern of the system."},
+	}
+	depthArgs := graphql.FieldConfigArgument{
+		"depth": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+	}
+
+	systemType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "System",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := envelopeFields()
+			fields["containers"] = &graphql.Field{
+				Type: graphql.NewList(containerType),
+				Args: filterArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					system := p.Source.(catalog.System)
+					p.Args["system"] = system.Name
+					return resolveContainers(p)
+				},
+			}
+			fields["apis"] = &graphql.Field{
+				Type: graphql.NewList(apiType),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					system := p.Source.(catalog.System)
+					landscape, _, err := rootOf(p).load()
+					if err != nil {
+						return nil, err
+					}
+					var apis []catalog.API
+					for _, api := range landscape.APIs {
+						if api.System == system.Name {
+							apis = append(apis, api)
+						}
+					}
+					return apis, nil
+				},
+			}
+			fields["dependsOn"] = &graphql.Field{
+				Type:        graphql.NewList(systemType),
+				Description: "Systems depended on up to the given depth.",
+				Args:        depthArgs,
+				Resolve:     resolveTraversal(analysis.LevelSystem, analysis.DirectionDownstream),
+			}
+			fields["dependents"] = &graphql.Field{
+				Type:        graphql.NewList(systemType),
+				Description: "Systems depending on the system up to the given depth.",
+				Args:        depthArgs,
+				Resolve:     resolveTraversal(analysis.LevelSystem, analysis.DirectionUpstream),
+			}
+			return fields
+		}),
+	})
+
+	containerType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Container",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := envelopeFields()
+			fields["system"] = &graphql.Field{
+				Type: systemType,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					container := p.Source.(catalog.Container)
+					return findEntity(p, catalog.EntityRef("System", container.System))
+				},
+			}
+			fields["consumes"] = &graphql.Field{
+				Type: graphql.NewList(apiType),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					container := p.Source.(catalog.Container)
+					return findRelated(p, container.Ref(), "CONSUMES", false)
+				},
+			}
+			fields["dependsOn"] = &graphql.Field{
+				Type:        graphql.NewList(containerType),
+				Description: "Containers depended on up to the given depth.",
+				Args:        depthArgs,
+				Resolve:     resolveTraversal(analysis.LevelContainer, analysis.DirectionDownstream),
+			}
+			fields["dependents"] = &graphql.Field{
+				Type:        graphql.NewList(containerType),
+				Description: "Containers depending on the container up to the given depth.",
+				Args:        depthArgs,
+				Resolve:     resolveTraversal(analysis.LevelContainer, analysis.DirectionUpstream),
+			}
+			return fields
+		}),
+	})
+
+	apiType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "API",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := envelopeFields()
+			fields["system"] = &graphql.Field{
+				Type: systemType,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					api := p.Source.(catalog.API)
+					return findEntity(p, catalog.EntityRef("System", api.System))
+				},
+			}
+			fields["consumers"] = &graphql.Field{
+				Type: graphql.NewList(containerType),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					api := p.Source.(catalog.API)
+					return findRelated(p, api.Ref(), "CONSUMES", true)
+				},
+			}
+			return fields
+		}),
+	})
+
+	relationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Relation",
+		Fields: graphql.Fields{
+			"source": &graphql.Field{Type: graphql.String},
+			"target": &graphql.Field{Type: graphql.String},
+			"type":   &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"systems": &graphql.Field{
+				Type:    graphql.NewList(systemType),
+				Args:    filterArgs,
+				Resolve: resolveSystems,
+			},
+			"system": &graphql.Field{
+				Type: systemType,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return findEntity(p, catalog.EntityRef("System", p.Args["name"].(string)))
+				},
+			},
+			"containers": &graphql.Field{
+				Type:    graphql.NewList(containerType),
+				Args:    filterArgs,
+				Resolve: resolveContainers,
+			},
+			"container": &graphql.Field{
+				Type: containerType,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return findEntity(p, catalog.EntityRef("Component", p.Args["name"].(string)))
+				},
+			},
+			"apis": &graphql.Field{
+				Type:    graphql.NewList(apiType),
+				Args:    filterArgs,
+				Resolve: resolveAPIs,
+			},
+			"relations": &graphql.Field{
+				Type: graphql.NewList(relationType),
+				Args: graphql.FieldConfigArgument{
+					"type":   &graphql.ArgumentConfig{Type: graphql.String},
+					"source": &graphql.ArgumentConfig{Type: graphql.String, Description: "Entity ref like `system:shop`."},
+					"target": &graphql.ArgumentConfig{Type: graphql.String, Description: "Entity ref like `system:shop`."},
+				},
+				Resolve: resolveRelations,
+			},
+			"diagram": &graphql.Field{
+				Type: diagramType(),
+				Args: graphql.FieldConfigArgument{
+					"view": &graphql.ArgumentConfig{
+						Type:         graphql.String,
+						DefaultValue: ViewContext,
+						Description:  "Either `context` or `container`.",
+					},
+					"system": &graphql.ArgumentConfig{
+						Type:        graphql.String,
+						Description: "Name of the system of a container diagram.",
+					},
+				},
+				Resolve: resolveDiagram,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: queryType,
+	})
+}
+
+func envelopeFields() graphql.Fields {
+	fields := graphql.Fields{}
+	for name, value := range map[string]func(e catalog.EntityEnvelope) any{
+		"id":          func(e catalog.EntityEnvelope) any { return e.ID },
+		"name":        func(e catalog.EntityEnvelope) any { return e.Name },
+		"title":       func(e catalog.EntityEnvelope) any { return e.Title },
+		"description": func(e catalog.EntityEnvelope) any { return e.Description },
+		"kind":        func(e catalog.EntityEnvelope) any { return e.Kind },
+		"type":        func(e catalog.EntityEnvelope) any { return e.Type },
+		"lifecycle":   func(e catalog.EntityEnvelope) any { return e.Lifecycle },
+		"tags":        func(e catalog.EntityEnvelope) any { return e.Tags },
+		"defined":     func(e catalog.EntityEnvelope) any { return e.Defined },
+	} {
+		fieldType := graphql.Output(graphql.String)
+		switch name {
+		case "tags":
+			fieldType = graphql.NewList(graphql.String)
+		case "defined":
+			fieldType = graphql.Boolean
+		}
+		value := value
+		fields[name] = &graphql.Field{
+			Type: fieldType,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return value(envelopeOf(p.Source)), nil
+			},
+		}
+	}
+	fields["ref"] = &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return refOf(p.Source), nil
+		},
+	}
+	return fields
+}
+
+func diagramType() *graphql.Object {
+	elementType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "DiagramElement",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.String},
+			"label":       &graphql.Field{Type: graphql.String},
+			"title":       &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			"type":        &graphql.Field{Type: graphql.String},
+			"technology":  &graphql.Field{Type: graphql.String},
+			"system":      &graphql.Field{Type: graphql.String},
+			"tags":        &graphql.Field{Type: graphql.NewList(graphql.String)},
+		},
+	})
+
+	diagramRelationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "DiagramRelation",
+		Fields: graphql.Fields{
+			"sourceId":   &graphql.Field{Type: graphql.String},
+			"targetId":   &graphql.Field{Type: graphql.String},
+			"label":      &graphql.Field{Type: graphql.String},
+			"technology": &graphql.Field{Type: graphql.String},
+			"tags":       &graphql.Field{Type: graphql.NewList(graphql.String)},
+		},
+	})
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Diagram",
+		Fields: graphql.Fields{
+			"persons":         &graphql.Field{Type: graphql.NewList(elementType)},
+			"systems":         &graphql.Field{Type: graphql.NewList(elementType)},
+			"externalSystems": &graphql.Field{Type: graphql.NewList(elementType)},
+			"containers":      &graphql.Field{Type: graphql.NewList(elementType)},
+			"relations":       &graphql.Field{Type: graphql.NewList(diagramRelationType)},
+			"plantUML": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					diagram := p.Source.(*diagram)
+					sw := bytes.NewBufferString("")
+					err := c4.ExportToPlantUML(diagram.C4DiagramModel, diagram.View == ViewContainer, sw)
+					if err != nil {
+						return nil, err
+					}
+					return sw.String(), nil
+				},
+			},
+			"mermaid": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					diagram := p.Source.(*diagram)
+					sw := bytes.NewBufferString("")
+					err := c4.ExportToMermaid(diagram.C4DiagramModel, sw)
+					if err != nil {
+						return nil, err
+					}
+					return sw.String(), nil
+				},
+			},
+		},
+	})
+}
+
+// diagram is a C4 diagram model together with its view.
+type diagram struct {
+	*c4.C4DiagramModel
+	View string
+}
+
+func (d *diagram) Resolve(p graphql.ResolveParams) (any, error) {
+	switch p.Info.FieldName {
+	case "persons":
+		return d.Persons, nil
+	case "systems":
+		return d.Systems, nil
+	case "externalSystems":
+		return d.ExternalSystems, nil
+	case "containers":
+		return d.Containers, nil
+	case "relations":
+		return d.Relations, nil
+	}
+	return nil, nil
+}
+
+func resolveDiagram(p graphql.ResolveParams) (any, error) {
+	root := rootOf(p)
+	view, _ := p.Args["view"].(string)
+	system, _ := p.Args["system"].(string)
+
+	var c4Model *c4.C4DiagramModel
+	var err error
+	switch {
+	case view == ViewContext:
+		c4Model, err = root.C4.SystemLandscapeDiagram(root.Context, c4.DiagramFilter{})
+	case view == ViewContainer && system != "":
+		c4Model, err = root.C4.ContainerDiagram(root.Context, system, c4.DiagramFilter{})
+	case view == ViewContainer:
+		c4Model, err = root.C4.SystemLandscapeContainerDiagram(root.Context, c4.DiagramFilter{})
+	default:
+		return nil, fmt.Errorf("unknown view %v", view)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &diagram{C4DiagramModel: c4Model, View: view}, nil
+}
+
+func resolveSystems(p graphql.ResolveParams) (any, error) {
+	landscape, g, err := rootOf(p).load()
+	if err != nil {
+		return nil, err
+	}
+
+	selector := selectorOf(p.Args)
+	var systems []catalog.System
+	for _, system := range landscape.Systems {
+		if selector.Matches(g.Nodes[system.Ref()]) {
+			systems = append(systems, system)
+		}
+	}
+	return systems, nil
+}
+
+func resolveContainers(p graphql.ResolveParams) (any, error) {
+	landscape, g, err := rootOf(p).load()
+	if err != nil {
+		return nil, err
+	}
+
+	selector := selectorOf(p.Args)
+	var containers []catalog.Container
+	for _, container := range landscape.Containers {
+		if selector.Matches(g.Nodes[container.Ref()]) {
+			containers = append(containers, container)
+		}
+	}
+	return containers, nil
+}
+
+func resolveAPIs(p graphql.ResolveParams) (any, error) {
+	landscape, g, err := rootOf(p).load()
+	if err != nil {
+		return nil, err
+	}
+
+	selector := selectorOf(p.Args)
+	var apis []catalog.API
+	for _, api := range landscape.APIs {
+		if selector.Matches(g.Nodes[api.Ref()]) {
+			apis = append(apis, api)
+		}
+	}
+	return apis, nil
+}
+
+func resolveRelations(p graphql.ResolveParams) (any, error) {
+	landscape, _, err := rootOf(p).load()
+	if err != nil {
+		return nil, err
+	}
+
+	relationType, _ := p.Args["type"].(string)
+	source, _ := p.Args["source"].(string)
+	target, _ := p.Args["target"].(string)
+
+	var relations []catalog.Relation
+	for _, relation := range landscape.Relations {
+		if relationType != "" && relation.Type != relationType {
+			continue
+		}
+		if source != "" && relation.Source != source {
+			continue
+		}
+		if target != "" && relation.Target != target {
+			continue
+		}
+		relations = append(relations, relation)
+	}
+	return relations, nil
+}
+
+// resolveTraversal resolves all entities reached from the source entity in
+// the given direction up to the depth given by argument `depth`.
+func resolveTraversal(level string, direction string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		depth, _ := p.Args["depth"].(int)
+		if depth < 1 || depth > 10 {
+			return nil, fmt.Errorf("depth must be between 1 and 10")
+		}
+
+		landscape, _, err := rootOf(p).load()
+		if err != nil {
+			return nil, err
+		}
+
+		impact := analysis.Traverse(analysis.DependencyGraph(landscape, level), refOf(p.Source), direction, depth)
+
+		var entities []any
+		for _, hop := range impact.Hops {
+			entity, err := findEntity(p, hop.Node.Ref)
+			if err != nil {
+				return nil, err
+			}
+			if entity != nil {
+				entities = append(entities, entity)
+			}
+		}
+		return entities, nil
+	}
+}
+
+// findRelated finds all entities related to the entity with the given ref
+// by relations of the given type, following relations backwards if inverse.
+func findRelated(p graphql.ResolveParams, ref string, relationType string, inverse bool) ([]any, error) {
+	landscape, _, err := rootOf(p).load()
+	if err != nil {
+		return nil, err
+	}
+
+	var entities []any
+	for _, relation := range landscape.Relations {
+		if relation.Type != relationType {
+			continue
+		}
+
+		related := ""
+		if !inverse && relation.Source == ref {
+			related = relation.Target
+		}
+		if inverse && relation.Target == ref {
+			related = relation.Source
+		}
+		if related == "" {
+			continue
+		}
+
+		entity, err := findEntity(p, related)
+		if err != nil {
+			return nil, err
+		}
+		if entity != nil {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, nil
+}
+
+// findEntity finds the system, container or API with the given ref.
+func findEntity(p graphql.ResolveParams, ref string) (any, error) {
+	landscape, _, err := rootOf(p).load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, system := range landscape.Systems {
+		if system.Ref() == ref {
+			return system, nil
+		}
+	}
+	for _, container := range landscape.Containers {
+		if container.Ref() == ref {
+			return container, nil
+		}
+	}
+	for _, api := range landscape.APIs {
+		if api.Ref() == ref {
+			return api, nil
+		}
+	}
+	return nil, nil
+}
+
+func selectorOf(args map[string]any) analysis.Selector {
+	selector := analysis.Selector{}
+	if name, ok := args["name"].(string); ok {
+		selector.Names = []string{name}
+	}
+	if entityType, ok := args["type"].(string); ok {
+		selector.Types = []string{entityType}
+	}
+	if lifecycle, ok := args["lifecycle"].(string); ok {
+		selector.Lifecycles = []string{lifecycle}
+	}
+	if tag, ok := args["tag"].(string); ok {
+		selector.Tags = []string{tag}
+	}
+	if system, ok := args["system"].(string); ok {
+		selector.Systems = []string{system}
+	}
+	return selector
+}
+
+func envelopeOf(entity any) catalog.EntityEnvelope {
+	switch e := entity.(type) {
+	case catalog.System:
+		return e.EntityEnvelope
+	case catalog.Container:
+		return e.EntityEnvelope
+	case catalog.API:
+		return e.EntityEnvelope
+	}
+	return catalog.EntityEnvelope{}
+}
+
+func refOf(entity any) string {
+	switch e := entity.(type) {
+	case catalog.System:
+		return e.Ref()
+	case catalog.Container:
+		return e.Ref()
+	case catalog.API:
+		return e.Ref()
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/matryer/is"
+	"github.io/remast/c4stage/catalog"
+)
+
+type landscapeRepository struct {
+	catalog.CatalogRepository
+	landscape *catalog.Landscape
+}
+
+func (r *landscapeRepository) FindLandscape(ctx context.Context) (*catalog.Landscape, error) {
+	return r.landscape, nil
+}
+
+func query(t *testing.T, requestString string) string {
+	schema, err := NewSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := &Root{
+		Context: context.Background(),
+		Catalog: &landscapeRepository{
+			landscape: &catalog.Landscape{
+				Systems: []catalog.System{
+					{EntityEnvelope: catalog.EntityEnvelope{Name: "shop", Title: "Shop"}},
+					{EntityEnvelope: catalog.EntityEnvelope{Name: "orders", Title: "Orders"}},
+					{EntityEnvelope: catalog.EntityEnvelope{Name: "psp", Title: "PSP"}},
+				},
+				Containers: []catalog.Container{
+					{EntityEnvelope: catalog.EntityEnvelope{Name: "shop-ui", Type: "website"}, System: "shop"},
+					{EntityEnvelope: catalog.EntityEnvelope{Name: "orders-api", Type: "service"}, System: "orders"},
+				},
+				APIs: []catalog.API{
+					{EntityEnvelope: catalog.EntityEnvelope{Name: "orders-rest"}, System: "orders"},
+				},
+				Relations: []catalog.Relation{
+					{Source: "system:shop", Target: "system:orders", Type: "DEPENDS_ON"},
+					{Source: "system:orders", Target: "system:psp", Type: "DEPENDS_ON"},
+					{Source: "component:shop-ui", Target: "api:orders-rest", Type: "CONSUMES"},
+				},
+			},
+		},
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: requestString,
+		RootObject:    root.RootObject(),
+	})
+	if result.HasErrors() {
+		t.Fatal(result.Errors)
+	}
+
+	data, err := json.Marshal(result.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestQuerySystemsWithContainers(t *testing.T) {
+	is := is.New(t)
+
+	data := query(t, `{ systems(name: "s*") { name title containers { name type } } }`)
+
+	is.Equal(data, `{"systems":[{"containers":[{"name":"shop-ui","type":"website"}],"name":"shop","title":"Shop"}]}`)
+}
+
+func TestQueryDependenciesWithDepth(t *testing.T) {
+	is := is.New(t)
+
+	data := query(t, `{ system(name: "shop") { dependsOn(depth: 2) { ref } } }`)
+
+	is.Equal(data, `{"system":{"dependsOn":[{"ref":"system:orders"},{"ref":"system:psp"}]}}`)
+}
+
+func TestQueryConsumersOfAPI(t *testing.T) {
+	is := is.New(t)
+
+	data := query(t, `{ apis { name system { name } consumers { name } } }`)
+
+	is.Equal(data, `{"apis":[{"consumers":[{"name":"shop-ui"}],"name":"orders-rest","system":{"name":"orders"}}]}`)
+}
+
+func TestQueryRelationsByType(t *testing.T) {
+	is := is.New(t)
+
+	data := query(t, `{ relations(type: "CONSUMES") { source target } }`)
+
+	is.Equal(data, `{"relations":[{"source":"component:shop-ui","target":"api:orders-rest"}]}`)
+}
@@ -13,9 +13,11 @@ import (
 	"github.com/kelseyhightower/envconfig"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 	"github.com/sethvargo/go-retry"
+	"github.io/remast/c4stage/analysis"
 	"github.io/remast/c4stage/backstage"
 	"github.io/remast/c4stage/c4"
 	"github.io/remast/c4stage/catalog"
+	"github.io/remast/c4stage/graph"
 	"github.io/remast/c4stage/shared"
 )
 
@@ -80,11 +82,31 @@ func newApp() (*shared.Config, neo4j.DriverWithContext, *chi.Mux, error) {
 		Driver: driver,
 	}
 
-	err = catalogRepository.Reset(context.Background())
+	err = catalogRepository.Migrate(context.Background())
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if config.ResetOnStartup {
+		log.Println("Resetting catalog ...")
+		err = catalogRepository.Reset(context.Background())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	rules, err := analysis.LoadRuleSet(config.RulesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	importListeners := []catalog.ImportListener{
+		&analysis.FitnessService{
+			Rules:      rules,
+			Repository: catalogRepository,
+		},
+	}
+
 	log.Printf("Importing Backstage Catalog in %v seconds.", config.BackstageImportDelay)
 	if config.BackstageImportDelay != -1 {
 		time.AfterFunc(time.Duration(config.BackstageImportDelay)*time.Second, func() {
@@ -92,6 +114,7 @@ func newApp() (*shared.Config, neo4j.DriverWithContext, *chi.Mux, error) {
 			backstageImportService := backstage.BackstageImporter{
 				Config:     &config,
 				Repository: catalogRepository,
+				Listeners:  importListeners,
 			}
 
 			err = backstageImportService.ImportBackstageCatalog()
@@ -107,6 +130,7 @@ func newApp() (*shared.Config, neo4j.DriverWithContext, *chi.Mux, error) {
 		&backstage.ImportController{
 			Config:            &config,
 			CatalogRepository: catalogRepository,
+			Listeners:         importListeners,
 		},
 		&catalog.CatalogController{
 			Config:     &config,
@@ -115,17 +139,32 @@ func newApp() (*shared.Config, neo4j.DriverWithContext, *chi.Mux, error) {
 		&c4.C4Controller{
 			Config:     &config,
 			Repository: c4Repository,
+			Catalog:    catalogRepository,
+		},
+		&analysis.AnalysisController{
+			Config:     &config,
+			Repository: catalogRepository,
+			Rules:      rules,
+		},
+		&graph.GraphController{
+			Config:  &config,
+			Catalog: catalogRepository,
+			C4:      c4Repository,
 		},
 		&shared.VersionController{},
 	}
 
+	apiMiddlewares := []func(http.Handler) http.Handler{
+		catalog.SnapshotContext(catalogRepository, config.IsProduction()),
+	}
+
 	router := chi.NewRouter()
-	registerRoutes(router, apiHandlers)
+	registerRoutes(router, apiHandlers, apiMiddlewares)
 
 	return &config, driver, router, nil
 }
 
-func registerRoutes(router *chi.Mux, apiHandlers []shared.DomainHandler) {
+func registerRoutes(router *chi.Mux, apiHandlers []shared.DomainHandler, apiMiddlewares []func(http.Handler) http.Handler) {
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.Compress(5))
@@ -134,11 +173,12 @@ func registerRoutes(router *chi.Mux, apiHandlers []shared.DomainHandler) {
 		io.WriteString(w, "Welcome to C4Stage!")
 	})
 
-	router.Mount("/api", registerApiRoutes(apiHandlers))
+	router.Mount("/api", registerApiRoutes(apiHandlers, apiMiddlewares))
 }
 
-func registerApiRoutes(apiHandlers []shared.DomainHandler) http.Handler {
+func registerApiRoutes(apiHandlers []shared.DomainHandler, apiMiddlewares []func(http.Handler) http.Handler) http.Handler {
 	router := chi.NewRouter()
+	router.Use(apiMiddlewares...)
 
 	for _, apiHandler := range apiHandlers {
 		apiHandler.RegisterOpen(router)
@@ -11,11 +11,22 @@ type Config struct {
 	Db         string `default:"neo4j://localhost"`
 	DbUser     string `default:"neo4j"`
 	DbPassword string `default:"c4stage12345!"`
+	// ResetOnStartup deletes all entities of the catalog on startup.
+	ResetOnStartup bool `default:"false"`
 
 	PlantUMLServer string `default:"http://localhost:9090"`
+	// Renderer renders svg images, either plantuml with the PlantUML server
+	// or native without it. The native renderer renders no png and pdf images.
+	Renderer string `default:"plantuml"`
 
 	BackstageServer      string `default:"http://localhost:7007"`
 	BackstageImportDelay int    `default:"5"`
+
+	// SnapshotRetention is the number of catalog snapshots to keep.
+	SnapshotRetention int `default:"10"`
+
+	// RulesFile is the yaml file with the architecture fitness rules.
+	RulesFile string
 }
 
 func (c Config) IsProduction() bool {
@@ -17,8 +17,12 @@ var (
 const (
 	ContextKeyPrincipal contextKey = 0
 	ContextKeyTx        contextKey = 1
+	ContextKeySnapshot  contextKey = 2
 )
 
+// LiveSnapshot identifies the current graph of the catalog.
+const LiveSnapshot = "live"
+
 type RepositoryTxer interface {
 	InTx(ctx context.Context, txFuncs ...func(ctxWithTx context.Context) error) error
 }
@@ -41,3 +45,19 @@ func LogVersion() {
 	log.Printf("Running version=%s (BuildTime=%s) BuildWith=(%s) RunOn=%s/%s\n",
 		version, buildTime, buildGoVersion, runtime.GOOS, runtime.GOARCH)
 }
+
+// ContextWithSnapshot scopes all repository calls made with the returned
+// context to the given snapshot of the catalog.
+func ContextWithSnapshot(ctx context.Context, snapshot string) context.Context {
+	return context.WithValue(ctx, ContextKeySnapshot, snapshot)
+}
+
+// SnapshotFromContext returns the snapshot of the catalog the context is
+// scoped to, which defaults to the live graph.
+func SnapshotFromContext(ctx context.Context) string {
+	snapshot, ok := ctx.Value(ContextKeySnapshot).(string)
+	if !ok || snapshot == "" {
+		return LiveSnapshot
+	}
+	return snapshot
+}
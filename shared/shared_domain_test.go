@@ -1,6 +1,7 @@
 package shared
 
 import (
+	"context"
 	"testing"
 
 	"github.com/matryer/is"
@@ -21,3 +22,17 @@ func TestLogVersion(t *testing.T) {
 		LogVersion()
 	})
 }
+
+func TestSnapshotFromContextWithoutSnapshot(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(SnapshotFromContext(context.Background()), LiveSnapshot)
+}
+
+func TestSnapshotFromContextWithSnapshot(t *testing.T) {
+	is := is.New(t)
+
+	ctx := ContextWithSnapshot(context.Background(), "my-snapshot")
+
+	is.Equal(SnapshotFromContext(ctx), "my-snapshot")
+}